@@ -4,11 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"html"
 	"os"
 	"os/signal"
+	"regexp"
 	"runtime"
-	"strings"
 	"syscall"
 	"time"
 
@@ -19,7 +18,8 @@ import (
 	"github.com/zergo0/simple-system-monitor/internal/alerts"
 	"github.com/zergo0/simple-system-monitor/internal/config"
 	"github.com/zergo0/simple-system-monitor/internal/monitor"
-	"github.com/zergo0/simple-system-monitor/internal/render"
+	"github.com/zergo0/simple-system-monitor/internal/notify"
+	"github.com/zergo0/simple-system-monitor/internal/state"
 	"github.com/zergo0/simple-system-monitor/internal/telegram"
 )
 
@@ -38,17 +38,48 @@ func main() {
 		logger.Warn("log interval too small, defaulting to 1s", zap.Duration("interval", cfg.LogInterval))
 		cfg.LogInterval = time.Second
 	}
-	if cfg.CPUAlertWindow < 0 {
-		logger.Warn("cpu alert window invalid, disabling delay", zap.Duration("cpu_alert_window", cfg.CPUAlertWindow))
-		cfg.CPUAlertWindow = 0
+	sanitizeRule(logger, "cpu", &cfg.CPU, false)
+	sanitizeRule(logger, "cpu steal", &cfg.Steal, false)
+	sanitizeRule(logger, "cpu iowait", &cfg.IOWait, false)
+	sanitizeRule(logger, "mem", &cfg.Mem, false)
+	sanitizeRule(logger, "disk", &cfg.Disk, false)
+	sanitizeRule(logger, "swap", &cfg.Swap, false)
+	sanitizeRule(logger, "load", &cfg.Load, false)
+	sanitizeRule(logger, "disk util", &cfg.DiskUtil, false)
+	sanitizeRule(logger, "disk latency", &cfg.DiskLatency, false)
+	sanitizeRule(logger, "temp", &cfg.Temp, false)
+	sanitizeRule(logger, "net", &cfg.Net, false)
+	sanitizeRule(logger, "net errors", &cfg.NetErrors, false)
+	sanitizeRule(logger, "psi cpu", &cfg.PressureCPU, false)
+	sanitizeRule(logger, "psi memory", &cfg.PressureMemory, false)
+	sanitizeRule(logger, "psi io", &cfg.PressureIO, false)
+	for _, err := range cfg.MountRuleErrors {
+		logger.Warn("mount rule invalid, ignoring", zap.Error(err))
 	}
-	if cfg.MemAlertWindow < 0 {
-		logger.Warn("mem alert window invalid, disabling delay", zap.Duration("mem_alert_window", cfg.MemAlertWindow))
-		cfg.MemAlertWindow = 0
+	for i := range cfg.DiskMounts {
+		sanitizeRule(logger, "disk "+cfg.DiskMounts[i].Pattern, &cfg.DiskMounts[i].Rule, false)
 	}
-	if cfg.DiskAlertWindow < 0 {
-		logger.Warn("disk alert window invalid, disabling delay", zap.Duration("disk_alert_window", cfg.DiskAlertWindow))
-		cfg.DiskAlertWindow = 0
+	sanitizeRule(logger, "inode", &cfg.Inodes, false)
+	for i := range cfg.InodeMounts {
+		sanitizeRule(logger, "inode "+cfg.InodeMounts[i].Pattern, &cfg.InodeMounts[i].Rule, false)
+	}
+	sanitizeRule(logger, "disk free", &cfg.DiskFree, true)
+	for i := range cfg.DiskFreeMounts {
+		sanitizeRule(logger, "disk free "+cfg.DiskFreeMounts[i].Pattern, &cfg.DiskFreeMounts[i].Rule, true)
+	}
+	for _, err := range cfg.ProcessErrors {
+		logger.Warn("process check invalid, ignoring", zap.Error(err))
+	}
+	if cfg.ProcessWindow < 0 {
+		logger.Warn("process alert window invalid, disabling delay", zap.Duration("process_alert_window", cfg.ProcessWindow))
+		cfg.ProcessWindow = 0
+	}
+	for _, err := range cfg.SilenceErrors {
+		logger.Warn("silence invalid, ignoring", zap.Error(err))
+	}
+
+	if cfg.DiskFillLookback < cfg.LogInterval*4 && (cfg.DiskFillWarn > 0 || cfg.DiskFillCrit > 0) {
+		logger.Warn("disk fill lookback too short for interval, forecast needs more samples", zap.Duration("disk_fill_lookback", cfg.DiskFillLookback), zap.Duration("interval", cfg.LogInterval))
 	}
 
 	hostname, err := os.Hostname()
@@ -64,16 +95,85 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), signalList()...)
 	defer stop()
 
-	var telegramClient *telegram.Client
+	routes := []notify.Route{}
 	if cfg.TelegramToken != "" && cfg.TelegramChatID != "" {
-		telegramClient = telegram.New(cfg.TelegramToken, cfg.TelegramChatID)
+		routes = append(routes, notifyRoute(notify.NewTelegram(telegram.New(cfg.TelegramToken, cfg.TelegramChatID)), cfg.TelegramRoute))
 	} else {
 		logger.Warn("telegram disabled: missing token or chat id")
 	}
+	if cfg.Webhook.URL != "" {
+		webhook := notify.NewWebhook(notify.WebhookConfig{
+			URL:     cfg.Webhook.URL,
+			Headers: cfg.Webhook.Headers,
+			Secret:  cfg.Webhook.Secret,
+			Timeout: cfg.Webhook.Timeout,
+			Retries: cfg.Webhook.Retries,
+		}, nil)
+		routes = append(routes, notifyRoute(webhook, cfg.Webhook.Route))
+	}
+	if cfg.Email.Host != "" {
+		email := notify.NewEmail(notify.EmailConfig{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+			Security: cfg.Email.Security,
+			Auth:     cfg.Email.Auth,
+			Timeout:  cfg.Email.Timeout,
+		})
+		if email != nil {
+			routes = append(routes, notifyRoute(email, cfg.Email.Route))
+		} else {
+			logger.Warn("email disabled: missing sender or recipients")
+		}
+	}
+	if cfg.Slack.URL != "" {
+		slack := notify.NewSlack(notify.SlackConfig{
+			URL:      cfg.Slack.URL,
+			Channel:  cfg.Slack.Channel,
+			Username: cfg.Slack.Username,
+			Timeout:  cfg.Slack.Timeout,
+		}, nil)
+		routes = append(routes, notifyRoute(slack, cfg.Slack.Route))
+	}
+	if cfg.Discord.URL != "" {
+		discord := notify.NewDiscord(notify.DiscordConfig{
+			URL:      cfg.Discord.URL,
+			Username: cfg.Discord.Username,
+			Timeout:  cfg.Discord.Timeout,
+			Retries:  cfg.Discord.Retries,
+		}, nil)
+		routes = append(routes, notifyRoute(discord, cfg.Discord.Route))
+	}
+	dispatcher := notify.NewDispatcher(logger, routes, cfg.LogInterval)
 
-	sendTelegramAtStart := telegramClient != nil
+	collector := monitor.NewCollector(logger, displayName, monitor.FilterConfig{
+		MountInclude:  cfg.MountInclude,
+		MountExclude:  cfg.MountExclude,
+		FstypeExclude: cfg.FstypeExclude,
+		IfaceInclude:  cfg.IfaceInclude,
+		IfaceExclude:  cfg.IfaceExclude,
+		SensorInclude: cfg.TempInclude,
+		SensorExclude: cfg.TempExclude,
+	}, monitor.Sources{SysfsRoot: cfg.SysfsRoot, ProcfsRoot: cfg.ProcfsRoot}, processChecks(logger, cfg.Processes))
+	thresholds := alertThresholds(cfg)
+	thresholds.Silences = alertSilences(logger, cfg.Silences)
+
+	store := state.New(cfg.StateDir)
+	snapshot, err := store.Load(time.Now(), cfg.StateMaxAge)
+	if err != nil {
+		logger.Warn("state load failed, starting fresh", zap.String("path", store.Path()), zap.Error(err))
+	} else if store != nil {
+		logger.Info("state loaded", zap.String("path", store.Path()), zap.Int("alerts", len(snapshot.Alerts.Metrics)))
+	}
+	alertState := snapshot.Alerts
+	lastTelegramAt := snapshot.LastTelegramAt
+
+	sendReportAtStart := dispatcher.Reports() && lastTelegramAt.IsZero()
 	var telegramSchedule cron.Schedule
-	if telegramClient != nil {
+	if dispatcher.Reports() {
 		if cfg.TelegramSchedule == "" {
 			logger.Warn("telegram schedule disabled: empty schedule")
 		} else {
@@ -86,16 +186,22 @@ func main() {
 		}
 	}
 
-	alertState := alerts.NewState()
-
 	now := time.Now()
-	if err := runOnce(ctx, logger, telegramClient, displayName, cfg, alertState, now, sendTelegramAtStart); err != nil {
+	if err := runOnce(ctx, logger, dispatcher, collector, cfg, hostname, thresholds, alertState, now, sendReportAtStart); err != nil {
 		logger.Error("initial run failed", zap.Error(err))
 	}
+	if sendReportAtStart {
+		lastTelegramAt = now
+	}
+	saveState(logger, store, alertState, lastTelegramAt)
 
 	nextTelegramAt := time.Time{}
 	if telegramSchedule != nil {
-		nextTelegramAt = telegramSchedule.Next(time.Now().UTC())
+		if lastTelegramAt.IsZero() {
+			nextTelegramAt = telegramSchedule.Next(time.Now().UTC())
+		} else {
+			nextTelegramAt = telegramSchedule.Next(lastTelegramAt.UTC())
+		}
 	}
 
 	ticker := time.NewTicker(cfg.LogInterval)
@@ -105,6 +211,7 @@ func main() {
 		select {
 		case <-ctx.Done():
 			logger.Info("shutdown", zap.String("reason", ctx.Err().Error()))
+			saveState(logger, store, alertState, lastTelegramAt)
 			return
 		case <-ticker.C:
 			now = time.Now()
@@ -116,19 +223,19 @@ func main() {
 					nextTelegramAt = telegramSchedule.Next(nowUTC)
 				}
 			}
-			if err := runOnce(ctx, logger, telegramClient, displayName, cfg, alertState, now, sendNow); err != nil {
+			if err := runOnce(ctx, logger, dispatcher, collector, cfg, hostname, thresholds, alertState, now, sendNow); err != nil {
 				logger.Error("run failed", zap.Error(err))
 			}
+			if sendNow {
+				lastTelegramAt = now
+			}
+			saveState(logger, store, alertState, lastTelegramAt)
 		}
 	}
 }
 
-func runOnce(ctx context.Context, logger *zap.Logger, telegramClient *telegram.Client, hostname string, cfg config.Config, alertState *alerts.AlertState, now time.Time, sendTelegramMetrics bool) error {
-	metrics, err := monitor.Collect(ctx, logger, hostname, monitor.FilterConfig{
-		MountInclude:  cfg.MountInclude,
-		MountExclude:  cfg.MountExclude,
-		FstypeExclude: cfg.FstypeExclude,
-	})
+func runOnce(ctx context.Context, logger *zap.Logger, dispatcher *notify.Dispatcher, collector *monitor.Collector, cfg config.Config, hostname string, thresholds alerts.Thresholds, alertState *alerts.AlertState, now time.Time, sendReport bool) error {
+	metrics, err := collector.Collect(ctx)
 	if err != nil {
 		return err
 	}
@@ -136,66 +243,292 @@ func runOnce(ctx context.Context, logger *zap.Logger, telegramClient *telegram.C
 	logger.Info("system metrics",
 		zap.String("hostname", metrics.Hostname),
 		zap.Float64("cpu_percent", metrics.CPUPercent),
+		zap.Any("cpu_times", metrics.CPUTimes),
+		zap.Float64s("cpu_core_percents", metrics.CPUCorePercents),
 		zap.Float64("mem_percent", metrics.MemPercent),
+		zap.Any("memory", metrics.Memory),
+		zap.Uint64("oom_kills", metrics.OOMKills),
+		zap.Float64("swap_percent", metrics.SwapPercent),
+		zap.Float64s("load", []float64{metrics.Load1, metrics.Load5, metrics.Load15}),
+		zap.Float64("load_per_core", metrics.LoadPerCore1),
+		zap.Uint64("uptime_seconds", metrics.Uptime),
 		zap.Any("disks", metrics.Disks),
+		zap.Any("disk_io", metrics.DiskIO),
+		zap.Any("interfaces", metrics.Interfaces),
+		zap.Any("temperatures", metrics.Temperatures),
+		zap.Any("processes", metrics.Processes),
+		zap.Any("pressure", metrics.Pressure),
 	)
 
-	if sendTelegramMetrics && telegramClient != nil {
-		header := formatMetricsHeaderHTML(metrics)
-		text := monitor.FormatMetricsText(metrics)
-		imageBytes, err := render.TextPNG(text)
-		if err != nil {
-			logger.Warn("telegram metrics render failed", zap.Error(err))
-		} else if err := telegramClient.SendPNGWithCaption(ctx, "metrics.png", imageBytes, header, "HTML"); err != nil {
-			logger.Warn("telegram metrics send failed", zap.Error(err))
+	message := notify.Message{Hostname: hostname, SystemName: cfg.SystemName, Metrics: metrics, Levels: statusLevels(cfg), At: now}
+	if sendReport && dispatcher.Reports() {
+		report := message
+		report.Type = notify.TypeReport
+		if cfg.TopReport {
+			report.Processes = topProcesses(ctx, logger, cfg.TopProcesses)
+		}
+		dispatcher.Send(ctx, report)
+	}
+
+	events := alerts.Check(metrics, thresholds, alertState, now)
+	if silenced := silencedEvents(events); len(silenced) > 0 {
+		logger.Info("alerts silenced", zap.String("hostname", metrics.Hostname), zap.Objects("alerts", silenced))
+	}
+	firing, resolved := alerts.Split(events)
+	if len(firing) > 0 {
+		logger.Warn("alerts triggered", zap.String("hostname", metrics.Hostname), zap.Objects("alerts", firing))
+		alert := message
+		alert.Type = notify.TypeAlert
+		alert.Events = firing
+		if dispatcher.Enabled() && processRelated(firing) {
+			alert.Processes = topProcesses(ctx, logger, cfg.TopProcesses)
+		}
+		dispatcher.Send(ctx, alert)
+	}
+	if len(resolved) > 0 {
+		logger.Info("alerts resolved", zap.String("hostname", metrics.Hostname), zap.Objects("alerts", resolved))
+		recovered := message
+		recovered.Type = notify.TypeResolved
+		recovered.Events = resolved
+		dispatcher.Send(ctx, recovered)
+	}
+
+	return nil
+}
+
+func saveState(logger *zap.Logger, store *state.Store, alertState *alerts.AlertState, lastTelegramAt time.Time) {
+	if store == nil {
+		return
+	}
+	if err := store.Save(state.Snapshot{SavedAt: time.Now(), LastTelegramAt: lastTelegramAt, Alerts: alertState}); err != nil {
+		logger.Warn("state save failed", zap.String("path", store.Path()), zap.Error(err))
+	}
+}
+
+func sanitizeRule(logger *zap.Logger, name string, rule *config.AlertRule, below bool) {
+	if rule.WarnWindow < 0 {
+		logger.Warn(name+" warn window invalid, disabling delay", zap.Duration(name+"_warn_window", rule.WarnWindow))
+		rule.WarnWindow = 0
+	}
+	if rule.CritWindow < 0 {
+		logger.Warn(name+" crit window invalid, disabling delay", zap.Duration(name+"_crit_window", rule.CritWindow))
+		rule.CritWindow = 0
+	}
+	if rule.ClearWindow < 0 {
+		logger.Warn(name+" clear window invalid, disabling delay", zap.Duration(name+"_clear_window", rule.ClearWindow))
+		rule.ClearWindow = 0
+	}
+	if rule.WarnClear > 0 && rule.WarnThreshold > 0 && rule.WarnClear != rule.WarnThreshold && (rule.WarnClear > rule.WarnThreshold) != below {
+		logger.Warn(name+" warn clear level on the wrong side of the threshold, ignoring", zap.Float64("warn", rule.WarnThreshold), zap.Float64("warn_clear", rule.WarnClear))
+		rule.WarnClear = 0
+	}
+	if rule.CritClear > 0 && rule.CritThreshold > 0 && rule.CritClear != rule.CritThreshold && (rule.CritClear > rule.CritThreshold) != below {
+		logger.Warn(name+" crit clear level on the wrong side of the threshold, ignoring", zap.Float64("crit", rule.CritThreshold), zap.Float64("crit_clear", rule.CritClear))
+		rule.CritClear = 0
+	}
+	if rule.WarnThreshold > 0 && rule.CritThreshold > 0 && (rule.WarnThreshold > rule.CritThreshold) != below && rule.WarnThreshold != rule.CritThreshold {
+		logger.Warn(name+" warn threshold is past crit threshold", zap.Float64("warn", rule.WarnThreshold), zap.Float64("crit", rule.CritThreshold))
+	}
+}
+
+func alertRule(rule config.AlertRule) alerts.Rule {
+	return alerts.Rule{
+		Warn:        alerts.Level{Threshold: rule.WarnThreshold, Window: rule.WarnWindow, Clear: rule.WarnClear},
+		Crit:        alerts.Level{Threshold: rule.CritThreshold, Window: rule.CritWindow, Clear: rule.CritClear},
+		ClearWindow: rule.ClearWindow,
+		Renotify:    rule.Renotify,
+	}
+}
+
+func statusLevel(rule config.AlertRule) monitor.Levels {
+	return monitor.Levels{Warn: rule.WarnThreshold, Crit: rule.CritThreshold}
+}
+
+func alertThresholds(cfg config.Config) alerts.Thresholds {
+	return alerts.Thresholds{
+		CPU:            alertRule(cfg.CPU),
+		Steal:          alertRule(cfg.Steal),
+		IOWait:         alertRule(cfg.IOWait),
+		Mem:            alertRule(cfg.Mem),
+		Swap:           alertRule(cfg.Swap),
+		Load:           alertRule(cfg.Load),
+		Disk:           alertRule(cfg.Disk),
+		DiskMounts:     mountRules(cfg.DiskMounts),
+		DiskFree:       alertRule(cfg.DiskFree),
+		DiskFreeMounts: mountRules(cfg.DiskFreeMounts),
+		DiskFill: alerts.Rule{
+			Warn:        alerts.Level{Threshold: cfg.DiskFillWarn.Seconds(), Window: cfg.Disk.WarnWindow},
+			Crit:        alerts.Level{Threshold: cfg.DiskFillCrit.Seconds(), Window: cfg.Disk.CritWindow},
+			ClearWindow: cfg.Disk.ClearWindow,
+			Renotify:    cfg.Disk.Renotify,
+		},
+		DiskFillLookback: cfg.DiskFillLookback,
+		Inodes:           alertRule(cfg.Inodes),
+		InodeMounts:      mountRules(cfg.InodeMounts),
+		DiskUtil:         alertRule(cfg.DiskUtil),
+		DiskLatency:      alertRule(cfg.DiskLatency),
+		Temp:             alertRule(cfg.Temp),
+		Net:              megabitRule(alertRule(cfg.Net)),
+		NetErrors:        alertRule(cfg.NetErrors),
+		Processes:        processRules(cfg),
+		PressureCPU:      alertRule(cfg.PressureCPU),
+		PressureMemory:   alertRule(cfg.PressureMemory),
+		PressureIO:       alertRule(cfg.PressureIO),
+		PressureWindow:   cfg.PressureWindow,
+		OOMKill:          oomKillRule(cfg),
+	}
+}
+
+func oomKillRule(cfg config.Config) alerts.Rule {
+	if !cfg.OOMKillAlert {
+		return alerts.Rule{}
+	}
+	return alerts.Rule{Crit: alerts.Level{Threshold: 1}}
+}
+
+func processChecks(logger *zap.Logger, checks []config.ProcessCheck) []monitor.ProcessCheck {
+	result := make([]monitor.ProcessCheck, 0, len(checks))
+	seen := make(map[string]struct{}, len(checks))
+	for _, check := range checks {
+		if _, ok := seen[check.Name]; ok {
+			logger.Warn("process check duplicated, ignoring", zap.String("name", check.Name))
+			continue
 		}
+		converted := monitor.ProcessCheck{Name: check.Name, Min: check.Min, Max: check.Max, Restart: check.Restart}
+		switch check.Match {
+		case "name":
+			converted.ProcessName = check.Pattern
+		case "cmdline":
+			pattern, err := regexp.Compile(check.Pattern)
+			if err != nil {
+				logger.Warn("process check pattern invalid, ignoring", zap.String("name", check.Name), zap.String("pattern", check.Pattern), zap.Error(err))
+				continue
+			}
+			converted.Cmdline = pattern
+		case "pidfile":
+			converted.PIDFile = check.Pattern
+		}
+		seen[check.Name] = struct{}{}
+		result = append(result, converted)
 	}
+	return result
+}
 
-	alertsList := alerts.Check(metrics, alerts.Thresholds{
-		CPUThreshold:    cfg.CPUThreshold,
-		CPUAlertWindow:  cfg.CPUAlertWindow,
-		MemThreshold:    cfg.MemThreshold,
-		MemAlertWindow:  cfg.MemAlertWindow,
-		DiskThreshold:   cfg.DiskThreshold,
-		DiskAlertWindow: cfg.DiskAlertWindow,
-	}, alertState, now)
-	if len(alertsList) > 0 {
-		logger.Warn("alerts triggered", zap.String("hostname", metrics.Hostname), zap.Strings("alerts", alertsList))
-		if telegramClient != nil {
-			header := formatAlertHeaderHTML(metrics.Hostname)
-			alertText := formatAlertBodyText(alertsList)
-			imageBytes, err := render.TextPNG(alertText)
+func processRules(cfg config.Config) []alerts.ProcessRule {
+	rules := make([]alerts.ProcessRule, 0, len(cfg.Processes))
+	for _, check := range cfg.Processes {
+		rule := alerts.ProcessRule{
+			Name:    check.Name,
+			Missing: alerts.Rule{Crit: alerts.Level{Threshold: float64(check.Min), Window: cfg.ProcessWindow}, Renotify: cfg.ProcessRenotify},
+		}
+		if check.Max > 0 {
+			rule.Excess = alerts.Rule{Warn: alerts.Level{Threshold: float64(check.Max + 1), Window: cfg.ProcessWindow}, Renotify: cfg.ProcessRenotify}
+		}
+		if check.Restart {
+			rule.Restart = alerts.Rule{Warn: alerts.Level{Threshold: 1}}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func alertSilences(logger *zap.Logger, silences []config.Silence) []alerts.Silence {
+	result := make([]alerts.Silence, 0, len(silences))
+	for _, silence := range silences {
+		converted := alerts.Silence{
+			Kind:     silence.Kind,
+			Resource: silence.Resource,
+			Start:    silence.Start,
+			End:      silence.End,
+			Duration: silence.Duration,
+		}
+		if silence.Schedule != "" {
+			schedule, err := cron.ParseStandard(silence.Schedule)
 			if err != nil {
-				logger.Warn("telegram alert render failed", zap.Error(err))
-			} else if err := telegramClient.SendPNGWithCaption(ctx, "alert.png", imageBytes, header, "HTML"); err != nil {
-				logger.Warn("telegram alert send failed", zap.Error(err))
+				logger.Warn("silence schedule invalid, ignoring", zap.String("schedule", silence.Schedule), zap.Error(err))
+				continue
 			}
+			converted.Schedule = schedule
 		}
+		result = append(result, converted)
 	}
+	return result
+}
 
-	return nil
+func silencedEvents(events []alerts.Event) []alerts.Event {
+	silenced := []alerts.Event{}
+	for _, event := range events {
+		if event.Silenced {
+			silenced = append(silenced, event)
+		}
+	}
+	return silenced
+}
+
+func megabitRule(rule alerts.Rule) alerts.Rule {
+	rule.Warn.Threshold *= 1e6
+	rule.Warn.Clear *= 1e6
+	rule.Crit.Threshold *= 1e6
+	rule.Crit.Clear *= 1e6
+	return rule
+}
+
+func mountRules(mounts []config.MountRule) []alerts.MountRule {
+	rules := make([]alerts.MountRule, 0, len(mounts))
+	for _, mount := range mounts {
+		rules = append(rules, alerts.MountRule{Pattern: mount.Pattern, Rule: alertRule(mount.Rule)})
+	}
+	return rules
 }
 
-func formatMetricsHeaderHTML(metrics monitor.Metrics) string {
-	headerText := monitor.FormatMetricsHeaderText(metrics)
-	return "<b>" + html.EscapeString(headerText) + "</b>"
+func statusLevels(cfg config.Config) monitor.StatusLevels {
+	return monitor.StatusLevels{
+		CPU:         statusLevel(cfg.CPU),
+		Steal:       statusLevel(cfg.Steal),
+		IOWait:      statusLevel(cfg.IOWait),
+		Mem:         statusLevel(cfg.Mem),
+		Swap:        statusLevel(cfg.Swap),
+		Load:        statusLevel(cfg.Load),
+		Disk:        statusLevel(cfg.Disk),
+		DiskMounts:  mountLevels(cfg.DiskMounts),
+		Inodes:      statusLevel(cfg.Inodes),
+		InodeMounts: mountLevels(cfg.InodeMounts),
+		Temp:        statusLevel(cfg.Temp),
+	}
 }
 
-func formatAlertHeaderHTML(hostname string) string {
-	host := monitor.CleanText(hostname)
-	headerText := "🚨 ALERT"
-	if host != "" {
-		headerText = fmt.Sprintf("%s - %s", headerText, host)
+func mountLevels(mounts []config.MountRule) []monitor.MountLevels {
+	levels := make([]monitor.MountLevels, 0, len(mounts))
+	for _, mount := range mounts {
+		levels = append(levels, monitor.MountLevels{Pattern: mount.Pattern, Levels: statusLevel(mount.Rule)})
 	}
-	return "<b>" + html.EscapeString(headerText) + "</b>"
+	return levels
 }
 
-func formatAlertBodyText(alertsList []string) string {
-	lines := make([]string, 0, len(alertsList))
-	for _, alert := range alertsList {
-		lines = append(lines, monitor.CleanText(alert))
+func processRelated(events []alerts.Event) bool {
+	for _, event := range events {
+		if event.Kind.WantsTopProcesses() {
+			return true
+		}
 	}
-	return strings.Join(lines, "\n")
+	return false
+}
+
+func topProcesses(ctx context.Context, logger *zap.Logger, n int) *monitor.TopProcesses {
+	if n <= 0 {
+		return nil
+	}
+	top, err := monitor.CollectTopProcesses(ctx, logger, n)
+	if err != nil {
+		logger.Warn("top processes collect failed", zap.Error(err))
+		return nil
+	}
+	logger.Info("top processes", zap.Any("by_cpu", top.ByCPU), zap.Any("by_mem", top.ByMem))
+	return &top
+}
+
+func notifyRoute(notifier notify.Notifier, route config.NotifyRoute) notify.Route {
+	return notify.Route{Notifier: notifier, MinSeverity: alerts.Severity(route.MinSeverity), Reports: route.Reports}
 }
 
 func signalList() []os.Signal {
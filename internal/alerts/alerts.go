@@ -1,103 +1,459 @@
 package alerts
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/zergo0/simple-system-monitor/internal/monitor"
 )
 
+type Level struct {
+	Threshold float64
+	Window    time.Duration
+	Clear     float64
+}
+
+type Rule struct {
+	Warn        Level
+	Crit        Level
+	ClearWindow time.Duration
+	Renotify    time.Duration
+}
+
+type MountRule struct {
+	Pattern string
+	Rule    Rule
+}
+
 type Thresholds struct {
-	CPUThreshold    float64
-	CPUAlertWindow  time.Duration
-	MemThreshold    float64
-	MemAlertWindow  time.Duration
-	DiskThreshold   float64
-	DiskAlertWindow time.Duration
+	CPU              Rule
+	Steal            Rule
+	IOWait           Rule
+	Mem              Rule
+	Swap             Rule
+	Load             Rule
+	Disk             Rule
+	DiskMounts       []MountRule
+	DiskFree         Rule
+	DiskFreeMounts   []MountRule
+	DiskFill         Rule
+	DiskFillLookback time.Duration
+	Inodes           Rule
+	InodeMounts      []MountRule
+	DiskUtil         Rule
+	DiskLatency      Rule
+	Temp             Rule
+	Net              Rule
+	NetErrors        Rule
+	Processes        []ProcessRule
+	PressureCPU      Rule
+	PressureMemory   Rule
+	PressureIO       Rule
+	PressureWindow   int
+	OOMKill          Rule
+	Silences         []Silence
+}
+
+type ProcessRule struct {
+	Name    string
+	Missing Rule
+	Excess  Rule
+	Restart Rule
+}
+
+type LevelState struct {
+	AboveSince time.Time `json:"above_since"`
+	BelowSince time.Time `json:"below_since"`
+	Active     bool      `json:"active"`
+}
+
+type MetricState struct {
+	Warn       LevelState `json:"warn"`
+	Crit       LevelState `json:"crit"`
+	Severity   Severity   `json:"severity,omitempty"`
+	Notified   Severity   `json:"notified,omitempty"`
+	Highest    Severity   `json:"highest,omitempty"`
+	Since      time.Time  `json:"since"`
+	NotifiedAt time.Time  `json:"notified_at"`
+	Peak       float64    `json:"peak"`
 }
 
 type AlertState struct {
-	CPUAboveSince  time.Time
-	CPUAlerting    bool
-	MemAboveSince  time.Time
-	MemAlerting    bool
-	DiskAboveSince map[string]time.Time
-	DiskAlerting   map[string]bool
+	Metrics     map[string]*MetricState `json:"metrics"`
+	DiskHistory map[string][]DiskSample `json:"disk_history"`
 }
 
 func NewState() *AlertState {
 	return &AlertState{
-		DiskAboveSince: make(map[string]time.Time),
-		DiskAlerting:   make(map[string]bool),
+		Metrics:     make(map[string]*MetricState),
+		DiskHistory: make(map[string][]DiskSample),
 	}
 }
 
-func Check(metrics monitor.Metrics, cfg Thresholds, state *AlertState, now time.Time) []string {
-	alerts := []string{}
-	if cfg.CPUThreshold > 0 {
-		if metrics.CPUPercent >= cfg.CPUThreshold {
-			if state.CPUAboveSince.IsZero() {
-				state.CPUAboveSince = now
+func (s *AlertState) Sanitize(now time.Time, stale bool) {
+	if s.Metrics == nil {
+		s.Metrics = make(map[string]*MetricState)
+	}
+	if s.DiskHistory == nil || stale {
+		s.DiskHistory = make(map[string][]DiskSample)
+	}
+	for key, state := range s.Metrics {
+		if state == nil || state.Since.After(now) || state.NotifiedAt.After(now) || state.Warn.AboveSince.After(now) || state.Crit.AboveSince.After(now) {
+			delete(s.Metrics, key)
+			continue
+		}
+		if state.Notified == "" && state.Severity != "" && !state.NotifiedAt.IsZero() {
+			state.Notified = state.Severity
+		}
+		if state.Highest == "" {
+			state.Highest = state.Notified
+		}
+		if stale {
+			if !state.Warn.Active {
+				state.Warn = LevelState{}
+			}
+			if !state.Crit.Active {
+				state.Crit = LevelState{}
 			}
-			if !state.CPUAlerting && now.Sub(state.CPUAboveSince) >= cfg.CPUAlertWindow {
-				alerts = append(alerts, fmt.Sprintf("CPU %.1f%% >= %.1f%% for %s", metrics.CPUPercent, cfg.CPUThreshold, cfg.CPUAlertWindow))
-				state.CPUAlerting = true
+			if state.Severity == "" && state.Notified == "" {
+				delete(s.Metrics, key)
 			}
-		} else if state.CPUAlerting || !state.CPUAboveSince.IsZero() {
-			state.CPUAlerting = false
-			state.CPUAboveSince = time.Time{}
 		}
 	}
-	if cfg.MemThreshold > 0 {
-		if metrics.MemPercent >= cfg.MemThreshold {
-			if state.MemAboveSince.IsZero() {
-				state.MemAboveSince = now
+	for mount, history := range s.DiskHistory {
+		kept := history[:0]
+		for _, sample := range history {
+			if !sample.At.After(now) {
+				kept = append(kept, sample)
 			}
-			if !state.MemAlerting && now.Sub(state.MemAboveSince) >= cfg.MemAlertWindow {
-				alerts = append(alerts, fmt.Sprintf("Memory %.1f%% >= %.1f%% for %s", metrics.MemPercent, cfg.MemThreshold, cfg.MemAlertWindow))
-				state.MemAlerting = true
-			}
-		} else if state.MemAlerting || !state.MemAboveSince.IsZero() {
-			state.MemAlerting = false
-			state.MemAboveSince = time.Time{}
 		}
+		if len(kept) == 0 {
+			delete(s.DiskHistory, mount)
+			continue
+		}
+		s.DiskHistory[mount] = kept
 	}
-	if cfg.DiskThreshold > 0 {
-		for _, d := range metrics.Disks {
-			mount := d.Mountpoint
-			if d.UsedPercent >= cfg.DiskThreshold {
-				if _, ok := state.DiskAboveSince[mount]; !ok {
-					state.DiskAboveSince[mount] = now
-				}
-				if !state.DiskAlerting[mount] && now.Sub(state.DiskAboveSince[mount]) >= cfg.DiskAlertWindow {
-					alerts = append(alerts, fmt.Sprintf("Disk %s %.1f%% >= %.1f%% for %s", mount, d.UsedPercent, cfg.DiskThreshold, cfg.DiskAlertWindow))
-					state.DiskAlerting[mount] = true
-				}
-			} else {
-				if state.DiskAlerting[mount] || !state.DiskAboveSince[mount].IsZero() {
-					delete(state.DiskAlerting, mount)
-					delete(state.DiskAboveSince, mount)
-				}
-			}
+}
+
+func (l Level) Enabled() bool {
+	return l.Threshold > 0
+}
+
+func (l Level) ClearThreshold() float64 {
+	if l.Clear == 0 {
+		return l.Threshold
+	}
+	return l.Clear
+}
+
+func (r Rule) Enabled() bool {
+	return r.Warn.Enabled() || r.Crit.Enabled()
+}
+
+func (r Rule) Level(severity Severity) Level {
+	if severity == SeverityWarning {
+		return r.Warn
+	}
+	return r.Crit
+}
+
+func (t Thresholds) DiskRule(mountpoint string) Rule {
+	return mountRule(t.Disk, t.DiskMounts, mountpoint)
+}
+
+func (t Thresholds) DiskFreeRule(mountpoint string) Rule {
+	return mountRule(t.DiskFree, t.DiskFreeMounts, mountpoint)
+}
+
+func (t Thresholds) InodeRule(mountpoint string) Rule {
+	return mountRule(t.Inodes, t.InodeMounts, mountpoint)
+}
+
+func (t Thresholds) ProcessRule(name string) ProcessRule {
+	for _, rule := range t.Processes {
+		if rule.Name == name {
+			return rule
 		}
-		pruneDiskState(state, metrics.Disks)
 	}
-	return alerts
+	return ProcessRule{Name: name}
 }
 
-func pruneDiskState(state *AlertState, disks []monitor.DiskUsage) {
-	active := make(map[string]struct{}, len(disks))
-	for _, d := range disks {
-		active[d.Mountpoint] = struct{}{}
+func mountRule(rule Rule, mounts []MountRule, mountpoint string) Rule {
+	best := -1
+	for _, mount := range mounts {
+		if score, ok := monitor.MatchMountPattern(mount.Pattern, mountpoint); ok && score > best {
+			best = score
+			rule = mount.Rule
+		}
 	}
-	for mount := range state.DiskAboveSince {
-		if _, ok := active[mount]; !ok {
-			delete(state.DiskAboveSince, mount)
+	return rule
+}
+
+func Check(metrics monitor.Metrics, cfg Thresholds, state *AlertState, now time.Time) []Event {
+	c := checker{state: state, now: now, seen: make(map[string]struct{}), silences: cfg.Silences}
+	c.eval(KindCPU, "", metrics.CPUPercent, cfg.CPU)
+	c.eval(KindSteal, "", metrics.CPUTimes.Steal, cfg.Steal)
+	c.eval(KindIOWait, "", metrics.CPUTimes.IOWait, cfg.IOWait)
+	c.eval(KindMem, "", metrics.MemPercent, cfg.Mem)
+	c.eval(KindSwap, "", metrics.SwapPercent, cfg.Swap)
+	c.count(KindOOMKill, "", float64(metrics.OOMKills), cfg.OOMKill)
+	c.eval(KindLoad, "", metrics.LoadPerCore1, cfg.Load)
+	if metrics.Pressure.Available {
+		c.eval(KindPressureCPU, "", metrics.Pressure.CPU.Some.Window(cfg.PressureWindow), cfg.PressureCPU)
+		c.eval(KindPressureMemory, "", metrics.Pressure.Memory.Some.Window(cfg.PressureWindow), cfg.PressureMemory)
+		c.eval(KindPressureIO, "", metrics.Pressure.IO.Some.Window(cfg.PressureWindow), cfg.PressureIO)
+	}
+	for _, d := range metrics.Disks {
+		c.eval(KindDisk, d.Mountpoint, d.UsedPercent, cfg.DiskRule(d.Mountpoint))
+		c.eval(KindDiskFree, d.Mountpoint, float64(d.FreeBytes), cfg.DiskFreeRule(d.Mountpoint))
+		if d.InodesTotal > 0 {
+			c.eval(KindDiskInodes, d.Mountpoint, d.InodesUsedPercent, cfg.InodeRule(d.Mountpoint))
 		}
+		c.forecastDisk(d, cfg.DiskFill, cfg.DiskFillLookback)
+	}
+	for _, io := range metrics.DiskIO {
+		c.eval(KindDiskUtil, io.Device, io.UtilPercent, cfg.DiskUtil)
+		c.eval(KindDiskLatency, io.Device, io.AwaitMs, cfg.DiskLatency)
+	}
+	for _, temp := range metrics.Temperatures {
+		c.eval(KindTemp, temp.Sensor, temp.Celsius, cfg.Temp)
+	}
+	for _, iface := range metrics.Interfaces {
+		c.eval(KindNetRx, iface.Name, iface.RxBytesPerSec*8, cfg.Net)
+		c.eval(KindNetTx, iface.Name, iface.TxBytesPerSec*8, cfg.Net)
+		c.count(KindNetErrors, iface.Name, float64(iface.Errors()), cfg.NetErrors)
+	}
+	for _, proc := range metrics.Processes {
+		rule := cfg.ProcessRule(proc.Name)
+		c.eval(KindProcessMissing, proc.Name, float64(proc.Count), rule.Missing)
+		c.eval(KindProcessExcess, proc.Name, float64(proc.Count), rule.Excess)
+		c.count(KindProcessRestart, proc.Name, float64(proc.Restarts), rule.Restart)
+	}
+	c.prune()
+	c.pruneHistory()
+	return c.events
+}
+
+type checker struct {
+	state    *AlertState
+	now      time.Time
+	seen     map[string]struct{}
+	silences []Silence
+	events   []Event
+}
+
+func (c *checker) eval(kind Kind, resource string, value float64, rule Rule) {
+	c.evalEvent(Event{Kind: kind, Resource: resource, Value: value}, rule)
+}
+
+func (c *checker) evalEvent(event Event, rule Rule) {
+	if !rule.Enabled() {
+		return
+	}
+	kind := event.Kind
+	value := event.Value
+	key := stateKey(kind, event.Resource)
+	c.seen[key] = struct{}{}
+	state, ok := c.state.Metrics[key]
+	if !ok {
+		state = &MetricState{}
+		c.state.Metrics[key] = state
 	}
-	for mount := range state.DiskAlerting {
-		if _, ok := active[mount]; !ok {
-			delete(state.DiskAlerting, mount)
+
+	below := kind.Below()
+	wasPending := !state.Warn.AboveSince.IsZero() || !state.Crit.AboveSince.IsZero() || state.Severity != "" || state.Notified != ""
+	updateLevel(&state.Crit, rule.Crit, rule.ClearWindow, value, below, c.now)
+	updateLevel(&state.Warn, rule.Warn, rule.ClearWindow, value, below, c.now)
+	if state.Crit.Active && rule.Warn.Enabled() && breached(value, rule.Warn.Threshold, below) {
+		state.Warn.Active = true
+	}
+
+	pending := !state.Warn.AboveSince.IsZero() || !state.Crit.AboveSince.IsZero()
+	if pending && (!wasPending || worse(value, state.Peak, below)) {
+		state.Peak = value
+	}
+
+	severity := Severity("")
+	switch {
+	case state.Crit.Active:
+		severity = SeverityCritical
+	case state.Warn.Active:
+		severity = SeverityWarning
+	}
+
+	previous := state.Severity
+	if severity != previous && previous == "" && state.Since.IsZero() {
+		state.Since = firstBreach(state)
+	}
+	state.Severity = severity
+
+	notified := state.Notified
+	silenced := c.silenced(kind, event.Resource)
+	switch {
+	case severity != notified && !silenced:
+		c.emit(state, event, rule, notified, severity, false)
+		state.Notified = severity
+		state.NotifiedAt = c.now
+		state.Highest = higher(state.Highest, severity)
+	case severity != notified && severity != previous:
+		c.emit(state, event, rule, previous, severity, true)
+	case severity != "" && !silenced && rule.Renotify > 0 && c.now.Sub(state.NotifiedAt) >= rule.Renotify:
+		event.Severity = severity
+		event.Transition = TransitionRepeat
+		c.fill(state, &event, rule)
+		state.NotifiedAt = c.now
+		c.events = append(c.events, event)
+	}
+
+	if severity == "" && state.Notified == "" && (previous != "" || notified != "") {
+		state.Since = time.Time{}
+		state.NotifiedAt = time.Time{}
+		state.Highest = ""
+		state.Peak = value
+	}
+	if severity == "" && state.Notified == "" && !pending {
+		delete(c.state.Metrics, key)
+	}
+}
+
+func (c *checker) count(kind Kind, resource string, value float64, rule Rule) {
+	if !rule.Enabled() {
+		return
+	}
+	severity := Severity("")
+	switch {
+	case rule.Crit.Enabled() && value >= rule.Crit.Threshold:
+		severity = SeverityCritical
+	case rule.Warn.Enabled() && value >= rule.Warn.Threshold:
+		severity = SeverityWarning
+	default:
+		return
+	}
+	c.events = append(c.events, Event{
+		Kind:       kind,
+		Resource:   resource,
+		Severity:   severity,
+		Transition: TransitionFiring,
+		Value:      value,
+		Peak:       value,
+		Threshold:  rule.Level(severity).Threshold,
+		Highest:    severity,
+		Since:      c.now,
+		At:         c.now,
+		Silenced:   c.silenced(kind, resource),
+	})
+}
+
+func (c *checker) emit(state *MetricState, event Event, rule Rule, from Severity, to Severity, silenced bool) {
+	event.Severity = to
+	event.Previous = from
+	switch {
+	case from == "":
+		event.Transition = TransitionFiring
+	case to == "":
+		event.Transition = TransitionResolved
+		event.Severity = from
+	case to == SeverityCritical:
+		event.Transition = TransitionEscalated
+	default:
+		event.Transition = TransitionDeescalated
+	}
+	event.Silenced = silenced
+	c.fill(state, &event, rule)
+	c.events = append(c.events, event)
+}
+
+func (c *checker) fill(state *MetricState, event *Event, rule Rule) {
+	level := rule.Level(event.Severity)
+	event.Threshold = level.Threshold
+	event.Window = level.Window
+	event.Peak = state.Peak
+	event.Highest = higher(state.Highest, event.Severity)
+	event.Since = state.Since
+	event.At = c.now
+}
+
+func (c *checker) silenced(kind Kind, resource string) bool {
+	for _, silence := range c.silences {
+		if silence.Matches(kind, resource) && silence.Active(c.now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) prune() {
+	for key := range c.state.Metrics {
+		if _, ok := c.seen[key]; !ok {
+			delete(c.state.Metrics, key)
+		}
+	}
+}
+
+func updateLevel(state *LevelState, level Level, clearWindow time.Duration, value float64, below bool, now time.Time) {
+	if !level.Enabled() {
+		*state = LevelState{}
+		return
+	}
+	if breached(value, level.Threshold, below) {
+		state.BelowSince = time.Time{}
+		if state.AboveSince.IsZero() {
+			state.AboveSince = now
+		}
+		if now.Sub(state.AboveSince) >= level.Window {
+			state.Active = true
 		}
+		return
+	}
+	if state.AboveSince.IsZero() {
+		return
+	}
+	if breached(value, level.ClearThreshold(), below) {
+		state.BelowSince = time.Time{}
+		return
+	}
+	if state.BelowSince.IsZero() {
+		state.BelowSince = now
+	}
+	if now.Sub(state.BelowSince) >= clearWindow {
+		*state = LevelState{}
+	}
+}
+
+func breached(value float64, threshold float64, below bool) bool {
+	if below {
+		return value < threshold
+	}
+	return value >= threshold
+}
+
+func worse(value float64, current float64, below bool) bool {
+	if below {
+		return value < current
+	}
+	return value > current
+}
+
+func higher(a Severity, b Severity) Severity {
+	if a == SeverityCritical || b == SeverityCritical {
+		return SeverityCritical
+	}
+	if a == SeverityWarning || b == SeverityWarning {
+		return SeverityWarning
+	}
+	return ""
+}
+
+func firstBreach(state *MetricState) time.Time {
+	since := state.Crit.AboveSince
+	if !state.Warn.AboveSince.IsZero() && (since.IsZero() || state.Warn.AboveSince.Before(since)) {
+		since = state.Warn.AboveSince
+	}
+	return since
+}
+
+func stateKey(kind Kind, resource string) string {
+	if resource == "" {
+		return string(kind)
 	}
+	return string(kind) + ":" + resource
 }
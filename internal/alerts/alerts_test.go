@@ -9,7 +9,7 @@ import (
 
 func TestCPUAlertWindow(t *testing.T) {
 	state := NewState()
-	cfg := Thresholds{CPUThreshold: 80, CPUAlertWindow: 5 * time.Minute}
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 80, Window: 5 * time.Minute}}}
 	metrics := monitor.Metrics{CPUPercent: 90}
 
 	start := time.Now()
@@ -30,14 +30,19 @@ func TestCPUAlertWindow(t *testing.T) {
 
 	metrics.CPUPercent = 10
 	alerts = Check(metrics, cfg, state, start.Add(7*time.Minute))
+	if len(alerts) != 1 || alerts[0].Transition != TransitionResolved {
+		t.Fatalf("expected resolved event after reset, got %#v", alerts)
+	}
+
+	alerts = Check(metrics, cfg, state, start.Add(8*time.Minute))
 	if len(alerts) != 0 {
-		t.Fatalf("expected no alert after reset")
+		t.Fatalf("expected no event after resolve")
 	}
 }
 
 func TestMemAlertWindow(t *testing.T) {
 	state := NewState()
-	cfg := Thresholds{MemThreshold: 70, MemAlertWindow: 2 * time.Minute}
+	cfg := Thresholds{Mem: Rule{Crit: Level{Threshold: 70, Window: 2 * time.Minute}}}
 	metrics := monitor.Metrics{MemPercent: 80}
 
 	start := time.Now()
@@ -54,7 +59,7 @@ func TestMemAlertWindow(t *testing.T) {
 
 func TestDiskAlertWindow(t *testing.T) {
 	state := NewState()
-	cfg := Thresholds{DiskThreshold: 80, DiskAlertWindow: 3 * time.Minute}
+	cfg := Thresholds{Disk: Rule{Crit: Level{Threshold: 80, Window: 3 * time.Minute}}}
 	metrics := monitor.Metrics{Disks: []monitor.DiskUsage{{Mountpoint: "/", UsedPercent: 85}}}
 
 	start := time.Now()
@@ -70,7 +75,614 @@ func TestDiskAlertWindow(t *testing.T) {
 
 	metrics.Disks = []monitor.DiskUsage{{Mountpoint: "/", UsedPercent: 10}}
 	alerts = Check(metrics, cfg, state, start.Add(4*time.Minute))
+	if len(alerts) != 1 || alerts[0].Transition != TransitionResolved {
+		t.Fatalf("expected resolved event after reset, got %#v", alerts)
+	}
+	if len(state.Metrics) != 0 {
+		t.Fatalf("expected disk state cleared, got %#v", state.Metrics)
+	}
+}
+
+func TestResolvedEventTracksPeakAndDuration(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 80, Window: time.Minute}}}
+
+	start := time.Now()
+	Check(monitor.Metrics{CPUPercent: 85}, cfg, state, start)
+	Check(monitor.Metrics{CPUPercent: 97}, cfg, state, start.Add(time.Minute))
+	Check(monitor.Metrics{CPUPercent: 90}, cfg, state, start.Add(2*time.Minute))
+
+	alerts := Check(monitor.Metrics{CPUPercent: 20}, cfg, state, start.Add(10*time.Minute))
+	if len(alerts) != 1 {
+		t.Fatalf("expected one resolved event, got %d", len(alerts))
+	}
+	event := alerts[0]
+	if event.Transition != TransitionResolved {
+		t.Fatalf("expected resolved transition, got %s", event.Transition)
+	}
+	if event.Kind != KindCPU || event.Severity != SeverityCritical {
+		t.Fatalf("unexpected event identity: %#v", event)
+	}
+	if event.Peak != 97 {
+		t.Fatalf("expected peak 97, got %.1f", event.Peak)
+	}
+	if !event.Since.Equal(start) {
+		t.Fatalf("expected since to be first breach")
+	}
+	if event.Duration() != 10*time.Minute {
+		t.Fatalf("expected duration 10m, got %s", event.Duration())
+	}
+}
+
+func TestNoResolvedEventWithoutFiring(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 80, Window: 5 * time.Minute}}}
+
+	start := time.Now()
+	Check(monitor.Metrics{CPUPercent: 90}, cfg, state, start)
+	alerts := Check(monitor.Metrics{CPUPercent: 10}, cfg, state, start.Add(time.Minute))
 	if len(alerts) != 0 {
-		t.Fatalf("expected no alert after reset")
+		t.Fatalf("expected no resolved event for pending alert, got %#v", alerts)
+	}
+}
+
+func TestDiskEventCarriesMountpoint(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Disk: Rule{Crit: Level{Threshold: 80}}}
+	metrics := monitor.Metrics{Disks: []monitor.DiskUsage{
+		{Mountpoint: "/", UsedPercent: 50},
+		{Mountpoint: "/var", UsedPercent: 91},
+	}}
+
+	alerts := Check(metrics, cfg, state, time.Now())
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %d", len(alerts))
+	}
+	if alerts[0].Kind != KindDisk || alerts[0].Resource != "/var" {
+		t.Fatalf("expected disk event for /var, got %#v", alerts[0])
+	}
+	if alerts[0].Threshold != 80 || alerts[0].Value != 91 {
+		t.Fatalf("expected value and threshold on event, got %#v", alerts[0])
+	}
+}
+
+func TestPruneRemovedDisk(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Disk: Rule{Crit: Level{Threshold: 80, Window: time.Minute}}}
+	start := time.Now()
+	Check(monitor.Metrics{Disks: []monitor.DiskUsage{{Mountpoint: "/mnt", UsedPercent: 95}}}, cfg, state, start)
+	if len(state.Metrics) != 1 {
+		t.Fatalf("expected pending disk state")
+	}
+	Check(monitor.Metrics{}, cfg, state, start.Add(time.Minute))
+	if len(state.Metrics) != 0 {
+		t.Fatalf("expected state pruned for removed disk, got %#v", state.Metrics)
+	}
+}
+
+func TestEscalateAndDeescalate(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{
+		Warn: Level{Threshold: 75, Window: time.Minute},
+		Crit: Level{Threshold: 90, Window: 2 * time.Minute},
+	}}
+
+	start := time.Now()
+	if events := Check(monitor.Metrics{CPUPercent: 80}, cfg, state, start); len(events) != 0 {
+		t.Fatalf("expected no event before warn window")
+	}
+	events := Check(monitor.Metrics{CPUPercent: 95}, cfg, state, start.Add(time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionFiring || events[0].Severity != SeverityWarning || events[0].HighestSeverity() != SeverityWarning {
+		t.Fatalf("expected warning firing, got %#v", events)
+	}
+	if events[0].Threshold != 75 {
+		t.Fatalf("expected warn threshold on event, got %.1f", events[0].Threshold)
+	}
+	events = Check(monitor.Metrics{CPUPercent: 95}, cfg, state, start.Add(3*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionEscalated || events[0].Severity != SeverityCritical || events[0].Previous != SeverityWarning {
+		t.Fatalf("expected escalation to critical, got %#v", events)
+	}
+	events = Check(monitor.Metrics{CPUPercent: 80}, cfg, state, start.Add(4*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionDeescalated || events[0].Severity != SeverityWarning || events[0].Highest != SeverityCritical {
+		t.Fatalf("expected de-escalation to warning, got %#v", events)
+	}
+	events = Check(monitor.Metrics{CPUPercent: 50}, cfg, state, start.Add(5*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionResolved || events[0].Severity != SeverityWarning || events[0].Highest != SeverityCritical {
+		t.Fatalf("expected resolve, got %#v", events)
+	}
+	if _, ok := state.Metrics[stateKey(KindCPU, "")]; ok {
+		t.Fatalf("expected state to be cleared after resolve")
+	}
+	if events[0].Peak != 95 || events[0].Duration() != 5*time.Minute {
+		t.Fatalf("expected peak 95 over 5m, got %.1f over %s", events[0].Peak, events[0].Duration())
+	}
+}
+
+func TestCritWithoutWarnLevel(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Mem: Rule{Crit: Level{Threshold: 90}}}
+	events := Check(monitor.Metrics{MemPercent: 80}, cfg, state, time.Now())
+	if len(events) != 0 {
+		t.Fatalf("expected no event below crit, got %#v", events)
+	}
+	events = Check(monitor.Metrics{MemPercent: 92}, cfg, state, time.Now())
+	if len(events) != 1 || events[0].Severity != SeverityCritical {
+		t.Fatalf("expected critical firing, got %#v", events)
+	}
+}
+
+func TestDiskRulePicksMostSpecificMount(t *testing.T) {
+	cfg := Thresholds{
+		Disk: Rule{Crit: Level{Threshold: 90}},
+		DiskMounts: []MountRule{
+			{Pattern: "/*", Rule: Rule{Crit: Level{Threshold: 85}}},
+			{Pattern: "/media*", Rule: Rule{Warn: Level{Threshold: 97}}},
+			{Pattern: "/boot", Rule: Rule{Crit: Level{Threshold: 80, Window: time.Minute}}},
+		},
+	}
+	if got := cfg.DiskRule("/boot"); got.Crit.Threshold != 80 || got.Crit.Window != time.Minute {
+		t.Fatalf("expected exact /boot rule, got %#v", got)
+	}
+	if got := cfg.DiskRule("/media/tv"); got.Warn.Threshold != 97 || got.Crit.Enabled() {
+		t.Fatalf("expected /media* rule, got %#v", got)
+	}
+	if got := cfg.DiskRule("/var"); got.Crit.Threshold != 85 {
+		t.Fatalf("expected /* rule, got %#v", got)
+	}
+	cfg.DiskMounts = nil
+	if got := cfg.DiskRule("/var"); got.Crit.Threshold != 90 {
+		t.Fatalf("expected global disk rule, got %#v", got)
+	}
+}
+
+func TestDiskMountOverrideAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{
+		Disk: Rule{Crit: Level{Threshold: 90}},
+		DiskMounts: []MountRule{
+			{Pattern: "/boot", Rule: Rule{Crit: Level{Threshold: 80}}},
+			{Pattern: "/media*", Rule: Rule{Warn: Level{Threshold: 97}}},
+		},
+	}
+	metrics := monitor.Metrics{Disks: []monitor.DiskUsage{
+		{Mountpoint: "/boot", UsedPercent: 82},
+		{Mountpoint: "/media/tv", UsedPercent: 93},
+		{Mountpoint: "/", UsedPercent: 50},
+	}}
+	events := Check(metrics, cfg, state, time.Now())
+	if len(events) != 1 || events[0].Resource != "/boot" || events[0].Threshold != 80 {
+		t.Fatalf("expected only /boot alert, got %#v", events)
+	}
+}
+
+func TestDiskFreeAlert(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	state := NewState()
+	cfg := Thresholds{
+		Disk:     Rule{Crit: Level{Threshold: 90}},
+		DiskFree: Rule{Crit: Level{Threshold: 10 * gib}},
+		DiskFreeMounts: []MountRule{
+			{Pattern: "/boot", Rule: Rule{Warn: Level{Threshold: 100 * 1024 * 1024}}},
+		},
+	}
+	metrics := monitor.Metrics{Disks: []monitor.DiskUsage{
+		{Mountpoint: "/", UsedPercent: 60, FreeBytes: 4 * gib},
+		{Mountpoint: "/boot", UsedPercent: 60, FreeBytes: 200 * 1024 * 1024},
+	}}
+	start := time.Now()
+	events := Check(metrics, cfg, state, start)
+	if len(events) != 1 || events[0].Kind != KindDiskFree || events[0].Resource != "/" {
+		t.Fatalf("expected disk free alert for /, got %#v", events)
+	}
+	if events[0].Value != 4*gib || events[0].Peak != 4*gib {
+		t.Fatalf("expected free bytes on event, got %#v", events[0])
+	}
+
+	metrics.Disks[0].FreeBytes = 2 * gib
+	Check(metrics, cfg, state, start.Add(time.Minute))
+	metrics.Disks[0].FreeBytes = 30 * gib
+	events = Check(metrics, cfg, state, start.Add(2*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionResolved {
+		t.Fatalf("expected disk free resolved, got %#v", events)
+	}
+	if events[0].Peak != 2*gib {
+		t.Fatalf("expected lowest free bytes tracked, got %.0f", events[0].Peak)
+	}
+}
+
+func TestDiskFillForecast(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	state := NewState()
+	cfg := Thresholds{
+		DiskFill:         Rule{Warn: Level{Threshold: (6 * time.Hour).Seconds()}},
+		DiskFillLookback: time.Hour,
+	}
+	start := time.Now()
+	disk := monitor.DiskUsage{Mountpoint: "/var", UsedPercent: 70, TotalBytes: 100 * gib}
+	var events []Event
+	for i := 0; i <= 6; i++ {
+		disk.UsedBytes = uint64(70*gib + i*gib)
+		disk.FreeBytes = disk.TotalBytes - disk.UsedBytes
+		events = Check(monitor.Metrics{Disks: []monitor.DiskUsage{disk}}, cfg, state, start.Add(time.Duration(i)*10*time.Minute))
+		if i < 3 && len(events) != 0 {
+			t.Fatalf("expected no forecast before half the lookback, got %#v", events)
+		}
+		if i == 3 {
+			break
+		}
+	}
+	if len(events) != 1 || events[0].Kind != KindDiskFill || events[0].Severity != SeverityWarning {
+		t.Fatalf("expected disk fill warning, got %#v", events)
+	}
+	wantRate := float64(gib) / 600
+	if diff := events[0].Rate - wantRate; diff > 1 || diff < -1 {
+		t.Fatalf("expected rate %.0f B/s, got %.0f", wantRate, events[0].Rate)
+	}
+	if eta := time.Duration(events[0].Value * float64(time.Second)).Round(time.Second); eta != 270*time.Minute {
+		t.Fatalf("expected eta 4h30m, got %s", eta)
+	}
+	if len(state.DiskHistory["/var"]) != 4 {
+		t.Fatalf("expected history within lookback, got %d samples", len(state.DiskHistory["/var"]))
+	}
+
+	for i := 4; i <= 14; i++ {
+		events = Check(monitor.Metrics{Disks: []monitor.DiskUsage{disk}}, cfg, state, start.Add(time.Duration(i)*10*time.Minute))
+		if len(events) > 0 {
+			break
+		}
+	}
+	if len(events) != 1 || events[0].Transition != TransitionResolved {
+		t.Fatalf("expected forecast to resolve once growth stops, got %#v", events)
+	}
+	if len(state.DiskHistory["/var"]) > 7 {
+		t.Fatalf("expected old samples trimmed, got %d", len(state.DiskHistory["/var"]))
+	}
+
+	Check(monitor.Metrics{}, cfg, state, start.Add(3*time.Hour))
+	if len(state.DiskHistory) != 0 {
+		t.Fatalf("expected history pruned for removed disk")
+	}
+}
+
+func TestGrowthRate(t *testing.T) {
+	start := time.Now()
+	history := []DiskSample{
+		{At: start, UsedBytes: 1000},
+		{At: start.Add(time.Minute), UsedBytes: 1060},
+		{At: start.Add(2 * time.Minute), UsedBytes: 1120},
+	}
+	rate, ok := growthRate(history, 2*time.Minute)
+	if !ok || rate != 1 {
+		t.Fatalf("expected 1 B/s, got %.3f (%v)", rate, ok)
+	}
+	if _, ok := growthRate(history[:2], time.Minute); ok {
+		t.Fatalf("expected too few samples to fail")
+	}
+	if _, ok := growthRate(history, time.Hour); ok {
+		t.Fatalf("expected short span to fail")
+	}
+}
+
+func TestRenotifyStillFiring(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Disk: Rule{Crit: Level{Threshold: 90}, Renotify: 6 * time.Hour}}
+	metrics := monitor.Metrics{Disks: []monitor.DiskUsage{{Mountpoint: "/", UsedPercent: 95}}}
+
+	start := time.Now()
+	if events := Check(metrics, cfg, state, start); len(events) != 1 || events[0].Transition != TransitionFiring {
+		t.Fatalf("expected initial firing, got %#v", events)
+	}
+	if events := Check(metrics, cfg, state, start.Add(5*time.Hour)); len(events) != 0 {
+		t.Fatalf("expected no repeat before interval, got %#v", events)
+	}
+	events := Check(metrics, cfg, state, start.Add(6*time.Hour))
+	if len(events) != 1 || events[0].Transition != TransitionRepeat {
+		t.Fatalf("expected repeat after interval, got %#v", events)
+	}
+	if events[0].Duration() != 6*time.Hour {
+		t.Fatalf("expected still firing for 6h, got %s", events[0].Duration())
+	}
+	if events := Check(metrics, cfg, state, start.Add(11*time.Hour)); len(events) != 0 {
+		t.Fatalf("expected repeat interval to restart, got %#v", events)
+	}
+	if events := Check(metrics, cfg, state, start.Add(12*time.Hour)); len(events) != 1 || events[0].Transition != TransitionRepeat {
+		t.Fatalf("expected second repeat, got %#v", events)
+	}
+}
+
+func TestRenotifyDisabled(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 90}}}
+	start := time.Now()
+	Check(monitor.Metrics{CPUPercent: 95}, cfg, state, start)
+	if events := Check(monitor.Metrics{CPUPercent: 95}, cfg, state, start.Add(72*time.Hour)); len(events) != 0 {
+		t.Fatalf("expected no repeat without interval, got %#v", events)
+	}
+}
+
+func TestHysteresisClearThreshold(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 90, Window: 3 * time.Minute, Clear: 80}}}
+	start := time.Now()
+	values := []float64{92, 86, 91, 89}
+	for i, value := range values {
+		if events := Check(monitor.Metrics{CPUPercent: value}, cfg, state, start.Add(time.Duration(i)*time.Minute)); len(events) != 0 {
+			t.Fatalf("expected no event while pending at %.0f, got %#v", value, events)
+		}
+	}
+	events := Check(monitor.Metrics{CPUPercent: 93}, cfg, state, start.Add(4*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionFiring {
+		t.Fatalf("expected firing despite dips inside the clear band, got %#v", events)
+	}
+	if !events[0].Since.Equal(start) {
+		t.Fatalf("expected window to start at the first breach")
+	}
+	if events := Check(monitor.Metrics{CPUPercent: 85}, cfg, state, start.Add(5*time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no resolve above clear threshold, got %#v", events)
+	}
+	events = Check(monitor.Metrics{CPUPercent: 79}, cfg, state, start.Add(6*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionResolved {
+		t.Fatalf("expected resolve below clear threshold, got %#v", events)
+	}
+}
+
+func TestHysteresisClearWindow(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Mem: Rule{Crit: Level{Threshold: 90}, ClearWindow: 2 * time.Minute}}
+	start := time.Now()
+	Check(monitor.Metrics{MemPercent: 95}, cfg, state, start)
+	if events := Check(monitor.Metrics{MemPercent: 50}, cfg, state, start.Add(time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no resolve before clear window, got %#v", events)
+	}
+	if events := Check(monitor.Metrics{MemPercent: 95}, cfg, state, start.Add(2*time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no new firing after short dip, got %#v", events)
+	}
+	Check(monitor.Metrics{MemPercent: 50}, cfg, state, start.Add(3*time.Minute))
+	events := Check(monitor.Metrics{MemPercent: 50}, cfg, state, start.Add(5*time.Minute))
+	if len(events) != 1 || events[0].Transition != TransitionResolved {
+		t.Fatalf("expected resolve after clear window, got %#v", events)
+	}
+}
+
+func TestHysteresisBelowKind(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	state := NewState()
+	cfg := Thresholds{DiskFree: Rule{Crit: Level{Threshold: 10 * gib, Clear: 12 * gib}}}
+	disk := monitor.DiskUsage{Mountpoint: "/", FreeBytes: 9 * gib}
+	start := time.Now()
+	Check(monitor.Metrics{Disks: []monitor.DiskUsage{disk}}, cfg, state, start)
+	disk.FreeBytes = 11 * gib
+	if events := Check(monitor.Metrics{Disks: []monitor.DiskUsage{disk}}, cfg, state, start.Add(time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no resolve inside clear band, got %#v", events)
+	}
+	disk.FreeBytes = 13 * gib
+	if events := Check(monitor.Metrics{Disks: []monitor.DiskUsage{disk}}, cfg, state, start.Add(2*time.Minute)); len(events) != 1 {
+		t.Fatalf("expected resolve above clear level, got %#v", events)
+	}
+}
+
+func TestSanitizeDropsFutureEntries(t *testing.T) {
+	now := time.Now()
+	state := &AlertState{Metrics: map[string]*MetricState{
+		"cpu": {Severity: SeverityCritical, Since: now.Add(time.Hour)},
+		"mem": {Severity: SeverityWarning, Since: now.Add(-time.Hour)},
+		"bad": nil,
+	}}
+	state.Sanitize(now, false)
+	if _, ok := state.Metrics["cpu"]; ok {
+		t.Fatalf("expected future entry dropped")
+	}
+	if _, ok := state.Metrics["bad"]; ok {
+		t.Fatalf("expected nil entry dropped")
+	}
+	if _, ok := state.Metrics["mem"]; !ok {
+		t.Fatalf("expected valid entry kept")
+	}
+	if state.DiskHistory == nil {
+		t.Fatalf("expected disk history initialized")
+	}
+}
+
+func TestSwapAndLoadAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{
+		Swap: Rule{Crit: Level{Threshold: 50}},
+		Load: Rule{Warn: Level{Threshold: 1}, Crit: Level{Threshold: 2}},
+	}
+	events := Check(monitor.Metrics{SwapPercent: 60, LoadPerCore1: 1.5}, cfg, state, time.Now())
+	if len(events) != 2 {
+		t.Fatalf("expected swap and load events, got %#v", events)
+	}
+	if events[0].Kind != KindSwap || events[0].Severity != SeverityCritical {
+		t.Fatalf("unexpected swap event: %#v", events[0])
+	}
+	if events[1].Kind != KindLoad || events[1].Severity != SeverityWarning {
+		t.Fatalf("unexpected load event: %#v", events[1])
+	}
+}
+
+func TestCPUTimeAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Steal: Rule{Warn: Level{Threshold: 10}}, IOWait: Rule{Crit: Level{Threshold: 20}}}
+	metrics := monitor.Metrics{CPUTimes: monitor.CPUTimes{Steal: 15, IOWait: 25}}
+	events := Check(metrics, cfg, state, time.Now())
+	if len(events) != 2 || events[0].Kind != KindSteal || events[1].Kind != KindIOWait {
+		t.Fatalf("expected steal and iowait events, got %#v", events)
+	}
+	if got := events[0].Text(); got != "WARN CPU steal 15.0% >= 10.0% for 0s" {
+		t.Fatalf("unexpected steal text: %q", got)
+	}
+}
+
+func TestNetworkAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Net: Rule{Crit: Level{Threshold: 500e6}}, NetErrors: Rule{Warn: Level{Threshold: 1}}}
+	metrics := monitor.Metrics{Interfaces: []monitor.NetUsage{{Name: "eth0", RxBytesPerSec: 100e6, TxBytesPerSec: 1e6, TxErrors: 3}}}
+	events := Check(metrics, cfg, state, time.Now())
+	if len(events) != 2 {
+		t.Fatalf("expected rx and errors events, got %#v", events)
+	}
+	if got := events[0].Text(); got != "CRIT Net rx eth0 800.0Mbit/s >= 500.0Mbit/s for 0s" {
+		t.Fatalf("unexpected rx text: %q", got)
+	}
+	if got := events[1].Text(); got != "WARN Net errors eth0: 3 new errors since the previous check" {
+		t.Fatalf("unexpected errors text: %q", got)
+	}
+	metrics.Interfaces[0].RxBytesPerSec = 0
+	metrics.Interfaces[0].TxErrors = 1
+	events = Check(metrics, cfg, state, time.Now().Add(time.Minute))
+	if len(events) != 2 || events[1].Kind != KindNetErrors || events[1].Transition != TransitionFiring || events[1].Text() != "WARN Net errors eth0: 1 new error since the previous check" {
+		t.Fatalf("expected errors to fire again while counters keep increasing, got %#v", events)
+	}
+	metrics.Interfaces[0].TxErrors = 0
+	if events = Check(metrics, cfg, state, time.Now().Add(2*time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no resolved event once counters stop increasing, got %#v", events)
+	}
+}
+
+func TestDiskIOAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{DiskUtil: Rule{Warn: Level{Threshold: 80}}, DiskLatency: Rule{Crit: Level{Threshold: 50}}}
+	metrics := monitor.Metrics{DiskIO: []monitor.DiskIO{{Device: "sdb", UtilPercent: 95, AwaitMs: 120}}}
+	events := Check(metrics, cfg, state, time.Now())
+	if len(events) != 2 || events[0].Kind != KindDiskUtil || events[1].Kind != KindDiskLatency {
+		t.Fatalf("expected util and latency events, got %#v", events)
+	}
+	if got := events[1].Text(); got != "CRIT Disk latency sdb 120.0ms >= 50.0ms for 0s" {
+		t.Fatalf("unexpected latency text: %q", got)
+	}
+}
+
+func TestInodeAlertPerMount(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{
+		Inodes:      Rule{Crit: Level{Threshold: 90, Window: time.Minute}},
+		InodeMounts: []MountRule{{Pattern: "/var*", Rule: Rule{Warn: Level{Threshold: 70}}}},
+	}
+	metrics := monitor.Metrics{Disks: []monitor.DiskUsage{
+		{Mountpoint: "/", UsedPercent: 40, InodesTotal: 100, InodesUsedPercent: 95},
+		{Mountpoint: "/var/lib", UsedPercent: 40, InodesTotal: 100, InodesUsedPercent: 75},
+		{Mountpoint: "/btrfs", UsedPercent: 40},
+	}}
+	start := time.Now()
+	events := Check(metrics, cfg, state, start)
+	if len(events) != 1 || events[0].Resource != "/var/lib" || events[0].Severity != SeverityWarning {
+		t.Fatalf("expected only the /var override to fire immediately, got %#v", events)
+	}
+	events = Check(metrics, cfg, state, start.Add(time.Minute))
+	if len(events) != 1 || events[0].Kind != KindDiskInodes || events[0].Resource != "/" {
+		t.Fatalf("expected / inode alert after window, got %#v", events)
+	}
+	if got := events[0].Text(); got != "CRIT Inodes / 95.0% >= 90.0% for 1m0s" {
+		t.Fatalf("unexpected inode text: %q", got)
+	}
+	if _, ok := state.Metrics[stateKey(KindDiskInodes, "/btrfs")]; ok {
+		t.Fatalf("expected no inode state for filesystems without inode counts")
+	}
+}
+
+func TestTemperatureAlert(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Temp: Rule{Crit: Level{Threshold: 85, Window: 2 * time.Minute}}}
+	metrics := monitor.Metrics{Temperatures: []monitor.Temperature{{Sensor: "coretemp/Package id 0", Celsius: 92}}}
+	start := time.Now()
+	if events := Check(metrics, cfg, state, start); len(events) != 0 {
+		t.Fatalf("expected no alert before window, got %#v", events)
+	}
+	events := Check(metrics, cfg, state, start.Add(2*time.Minute))
+	if len(events) != 1 || events[0].Text() != "CRIT Temp coretemp/Package id 0 92.0°C >= 85.0°C for 2m0s" {
+		t.Fatalf("unexpected temperature events: %#v", events)
+	}
+}
+
+func TestProcessAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{Processes: []ProcessRule{
+		{Name: "db", Missing: Rule{Crit: Level{Threshold: 1}}},
+		{Name: "nginx", Missing: Rule{Crit: Level{Threshold: 1}}, Excess: Rule{Warn: Level{Threshold: 5}}},
+		{Name: "app", Restart: Rule{Warn: Level{Threshold: 1}}},
+	}}
+	metrics := monitor.Metrics{Processes: []monitor.ProcessStatus{
+		{Name: "db", Count: 0, Min: 1},
+		{Name: "nginx", Count: 6, Min: 1, Max: 4},
+		{Name: "app", Count: 1, Restarts: 1, Min: 1},
+		{Name: "unknown", Count: 0},
+	}}
+	start := time.Now()
+	events := Check(metrics, cfg, state, start)
+	if len(events) != 3 {
+		t.Fatalf("expected missing, excess and restart events, got %#v", events)
+	}
+	for i, want := range []string{"CRIT Process db 0 < 1 for 0s", "WARN Process nginx 6 >= 5 for 0s", "WARN Process app restarted since the previous check"} {
+		if got := events[i].Text(); got != want {
+			t.Fatalf("event %d text = %q, want %q", i, got, want)
+		}
+	}
+
+	metrics.Processes[0].Count = 1
+	events = Check(metrics, cfg, state, start.Add(time.Minute))
+	if len(events) != 2 || events[0].Kind != KindProcessMissing || !events[0].Resolved() || events[1].Kind != KindProcessRestart || events[1].Transition != TransitionFiring {
+		t.Fatalf("expected db to resolve and back-to-back restarts to fire again, got %#v", events)
+	}
+	metrics.Processes[2].Restarts = 0
+	if events = Check(metrics, cfg, state, start.Add(2*time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no resolved event for restarts, got %#v", events)
+	}
+}
+
+func TestPressureAlerts(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{PressureIO: Rule{Warn: Level{Threshold: 20}}, PressureCPU: Rule{Crit: Level{Threshold: 50}}, PressureWindow: 300}
+	metrics := monitor.Metrics{Pressure: monitor.Pressure{
+		CPU: monitor.PressureStat{Some: monitor.PressureAvg{Avg10: 90, Avg60: 80, Avg300: 70}},
+		IO:  monitor.PressureStat{Some: monitor.PressureAvg{Avg10: 60, Avg60: 40, Avg300: 25}},
+	}}
+	if events := Check(metrics, cfg, state, time.Now()); len(events) != 0 {
+		t.Fatalf("expected no alerts while pressure is unavailable, got %#v", events)
+	}
+	metrics.Pressure.Available = true
+	events := Check(metrics, cfg, state, time.Now())
+	if len(events) != 2 || events[0].Kind != KindPressureCPU || events[1].Kind != KindPressureIO {
+		t.Fatalf("expected cpu and io pressure alerts, got %#v", events)
+	}
+	if got := events[1].Text(); got != "WARN IO pressure 25.0% >= 20.0% for 0s" {
+		t.Fatalf("unexpected pressure text: %q", got)
+	}
+}
+
+func TestOOMKillAlert(t *testing.T) {
+	state := NewState()
+	cfg := Thresholds{OOMKill: Rule{Crit: Level{Threshold: 1}}}
+	start := time.Now()
+	if events := Check(monitor.Metrics{}, cfg, state, start); len(events) != 0 {
+		t.Fatalf("expected no alert without kills, got %#v", events)
+	}
+	events := Check(monitor.Metrics{OOMKills: 2}, cfg, state, start.Add(time.Minute))
+	if len(events) != 1 || events[0].Text() != "CRIT OOM killer ran 2 times since the previous check" {
+		t.Fatalf("expected immediate oom kill alert, got %#v", events)
+	}
+	if !events[0].Kind.WantsTopProcesses() {
+		t.Fatalf("expected oom kills to attach top processes")
+	}
+	for i, kills := range []uint64{1, 0, 3} {
+		events = Check(monitor.Metrics{OOMKills: kills}, cfg, state, start.Add(time.Duration(i+2)*time.Minute))
+		if kills == 0 {
+			if len(events) != 0 {
+				t.Fatalf("expected no resolved event for oom kills, got %#v", events)
+			}
+			continue
+		}
+		if len(events) != 1 || events[0].Transition != TransitionFiring || events[0].Value != float64(kills) {
+			t.Fatalf("expected a firing event on every tick with kills, got %#v", events)
+		}
+	}
+	if got := events[0].Text(); got != "CRIT OOM killer ran 3 times since the previous check" {
+		t.Fatalf("unexpected oom kill text: %q", got)
+	}
+	if got := (Event{Kind: KindOOMKill, Severity: SeverityCritical, Value: 1}).Text(); got != "CRIT OOM killer ran 1 time since the previous check" {
+		t.Fatalf("unexpected singular text: %q", got)
+	}
+	if len(state.Metrics) != 0 {
+		t.Fatalf("expected no state for oom kills, got %#v", state.Metrics)
 	}
 }
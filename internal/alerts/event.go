@@ -0,0 +1,343 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/zergo0/simple-system-monitor/internal/units"
+)
+
+type Kind string
+
+const (
+	KindCPU            Kind = "cpu"
+	KindSteal          Kind = "cpu_steal"
+	KindIOWait         Kind = "cpu_iowait"
+	KindMem            Kind = "mem"
+	KindSwap           Kind = "swap"
+	KindLoad           Kind = "load"
+	KindDisk           Kind = "disk"
+	KindDiskFree       Kind = "disk_free"
+	KindDiskFill       Kind = "disk_fill"
+	KindDiskInodes     Kind = "disk_inodes"
+	KindDiskUtil       Kind = "disk_util"
+	KindDiskLatency    Kind = "disk_latency"
+	KindTemp           Kind = "temp"
+	KindNetRx          Kind = "net_rx"
+	KindNetTx          Kind = "net_tx"
+	KindNetErrors      Kind = "net_errors"
+	KindProcessMissing Kind = "process_missing"
+	KindProcessExcess  Kind = "process_excess"
+	KindProcessRestart Kind = "process_restart"
+	KindPressureCPU    Kind = "psi_cpu"
+	KindPressureMemory Kind = "psi_memory"
+	KindPressureIO     Kind = "psi_io"
+	KindOOMKill        Kind = "oom_kill"
+)
+
+type Unit string
+
+const (
+	UnitPercent Unit = "percent"
+	UnitBytes   Unit = "bytes"
+	UnitSeconds Unit = "seconds"
+	UnitRatio   Unit = "ratio"
+	UnitBits    Unit = "bits"
+	UnitCount   Unit = "count"
+	UnitMillis  Unit = "milliseconds"
+	UnitCelsius Unit = "celsius"
+)
+
+type Severity string
+
+const (
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+type Transition string
+
+const (
+	TransitionFiring      Transition = "firing"
+	TransitionEscalated   Transition = "escalated"
+	TransitionDeescalated Transition = "deescalated"
+	TransitionRepeat      Transition = "repeat"
+	TransitionResolved    Transition = "resolved"
+)
+
+type Event struct {
+	Kind       Kind          `json:"kind"`
+	Resource   string        `json:"resource,omitempty"`
+	Severity   Severity      `json:"severity"`
+	Previous   Severity      `json:"previous,omitempty"`
+	Highest    Severity      `json:"highest,omitempty"`
+	Transition Transition    `json:"transition"`
+	Value      float64       `json:"value"`
+	Peak       float64       `json:"peak"`
+	Threshold  float64       `json:"threshold"`
+	Window     time.Duration `json:"window"`
+	Rate       float64       `json:"rate,omitempty"`
+	Since      time.Time     `json:"since"`
+	At         time.Time     `json:"at"`
+	Silenced   bool          `json:"silenced,omitempty"`
+}
+
+func (e Event) Duration() time.Duration {
+	if e.Since.IsZero() || e.At.Before(e.Since) {
+		return 0
+	}
+	return e.At.Sub(e.Since)
+}
+
+func (e Event) Resolved() bool {
+	return e.Transition == TransitionResolved
+}
+
+func (e Event) HighestSeverity() Severity {
+	return higher(e.Highest, e.Severity)
+}
+
+func (k Kind) Name() string {
+	switch k {
+	case KindCPU:
+		return "CPU"
+	case KindSteal:
+		return "CPU steal"
+	case KindIOWait:
+		return "CPU iowait"
+	case KindMem:
+		return "Memory"
+	case KindSwap:
+		return "Swap"
+	case KindLoad:
+		return "Load/core"
+	case KindDisk:
+		return "Disk"
+	case KindDiskFree:
+		return "Disk free"
+	case KindDiskFill:
+		return "Disk fill"
+	case KindDiskInodes:
+		return "Inodes"
+	case KindDiskUtil:
+		return "Disk util"
+	case KindDiskLatency:
+		return "Disk latency"
+	case KindTemp:
+		return "Temp"
+	case KindNetRx:
+		return "Net rx"
+	case KindNetTx:
+		return "Net tx"
+	case KindNetErrors:
+		return "Net errors"
+	case KindProcessMissing, KindProcessExcess:
+		return "Process"
+	case KindProcessRestart:
+		return "Process restarts"
+	case KindPressureCPU:
+		return "CPU pressure"
+	case KindPressureMemory:
+		return "Memory pressure"
+	case KindPressureIO:
+		return "IO pressure"
+	case KindOOMKill:
+		return "OOM kills"
+	default:
+		return string(k)
+	}
+}
+
+func (k Kind) Unit() Unit {
+	switch k {
+	case KindDiskFree:
+		return UnitBytes
+	case KindDiskFill:
+		return UnitSeconds
+	case KindLoad:
+		return UnitRatio
+	case KindNetRx, KindNetTx:
+		return UnitBits
+	case KindNetErrors, KindProcessMissing, KindProcessExcess, KindProcessRestart, KindOOMKill:
+		return UnitCount
+	case KindDiskLatency:
+		return UnitMillis
+	case KindTemp:
+		return UnitCelsius
+	default:
+		return UnitPercent
+	}
+}
+
+func (k Kind) WantsTopProcesses() bool {
+	switch k {
+	case KindCPU, KindIOWait, KindMem, KindSwap, KindLoad, KindOOMKill:
+		return true
+	default:
+		return false
+	}
+}
+
+func (k Kind) Below() bool {
+	return k == KindDiskFree || k == KindDiskFill || k == KindProcessMissing
+}
+
+func (u Unit) Format(value float64) string {
+	switch u {
+	case UnitBytes:
+		return units.Bytes(value)
+	case UnitSeconds:
+		if value >= noForecast {
+			return "never"
+		}
+		return formatDuration(time.Duration(value * float64(time.Second)))
+	case UnitRatio:
+		return fmt.Sprintf("%.2f", value)
+	case UnitBits:
+		return units.BitRate(value)
+	case UnitCount:
+		return fmt.Sprintf("%.0f", value)
+	case UnitMillis:
+		return fmt.Sprintf("%.1fms", value)
+	case UnitCelsius:
+		return fmt.Sprintf("%.1f°C", value)
+	default:
+		return fmt.Sprintf("%.1f%%", value)
+	}
+}
+
+func (e Event) Label() string {
+	name := e.Kind.Name()
+	if e.Resource != "" {
+		return name + " " + e.Resource
+	}
+	return name
+}
+
+func (e Event) Text() string {
+	unit := e.Kind.Unit()
+	value := unit.Format(e.Value)
+	if e.Transition == TransitionResolved {
+		peakLabel := "peak"
+		if e.Kind.Below() {
+			peakLabel = "low"
+		}
+		if e.Kind == KindDiskFill {
+			return fmt.Sprintf("%s resolved after %s (ETA %s, low %s)", e.Label(), formatDuration(e.Duration()), value, unit.Format(e.Peak))
+		}
+		return fmt.Sprintf("%s %s resolved after %s (%s %s)", e.Label(), value, formatDuration(e.Duration()), peakLabel, unit.Format(e.Peak))
+	}
+	op := ">="
+	if e.Kind.Below() {
+		op = "<"
+	}
+	text := fmt.Sprintf("%s %s %s %s %s for %s", e.Severity.Tag(), e.Label(), value, op, unit.Format(e.Threshold), e.Window)
+	if e.Kind == KindOOMKill {
+		text = fmt.Sprintf("%s OOM killer ran %s since the previous check", e.Severity.Tag(), pluralize(e.Value, "time"))
+	}
+	if e.Kind == KindProcessRestart {
+		text = fmt.Sprintf("%s Process %s restarted since the previous check", e.Severity.Tag(), e.Resource)
+	}
+	if e.Kind == KindNetErrors {
+		text = fmt.Sprintf("%s Net errors %s: %s since the previous check", e.Severity.Tag(), e.Resource, pluralize(e.Value, "new error"))
+	}
+	if e.Kind == KindDiskFill {
+		text = fmt.Sprintf("%s Disk %s full in %s < %s at +%s/h for %s", e.Severity.Tag(), e.Resource, value, unit.Format(e.Threshold), units.Bytes(e.Rate*3600), e.Window)
+	}
+	switch e.Transition {
+	case TransitionEscalated:
+		text += fmt.Sprintf(" (escalated from %s)", e.Previous.Tag())
+	case TransitionDeescalated:
+		text += fmt.Sprintf(" (down from %s)", e.Previous.Tag())
+	case TransitionRepeat:
+		text += fmt.Sprintf(" (still firing for %s)", formatDuration(e.Duration()))
+	}
+	return text
+}
+
+func (s Severity) Tag() string {
+	switch s {
+	case SeverityWarning:
+		return "WARN"
+	case SeverityCritical:
+		return "CRIT"
+	default:
+		return "OK"
+	}
+}
+
+func (e Event) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("kind", string(e.Kind))
+	if e.Resource != "" {
+		enc.AddString("resource", e.Resource)
+	}
+	enc.AddString("severity", string(e.Severity))
+	enc.AddString("transition", string(e.Transition))
+	if e.Previous != "" {
+		enc.AddString("previous", string(e.Previous))
+	}
+	if e.Highest != "" && e.Highest != e.Severity {
+		enc.AddString("highest", string(e.Highest))
+	}
+	enc.AddFloat64("value", e.Value)
+	enc.AddFloat64("peak", e.Peak)
+	enc.AddFloat64("threshold", e.Threshold)
+	enc.AddDuration("window", e.Window)
+	if e.Rate != 0 {
+		enc.AddFloat64("rate", e.Rate)
+	}
+	enc.AddTime("since", e.Since)
+	enc.AddTime("at", e.At)
+	if e.Resolved() || e.Transition == TransitionRepeat {
+		enc.AddDuration("duration", e.Duration())
+	}
+	if e.Silenced {
+		enc.AddBool("silenced", true)
+	}
+	return nil
+}
+
+func Split(events []Event) ([]Event, []Event) {
+	firing := []Event{}
+	resolved := []Event{}
+	for _, event := range events {
+		if event.Silenced {
+			continue
+		}
+		if event.Resolved() {
+			resolved = append(resolved, event)
+		} else {
+			firing = append(firing, event)
+		}
+	}
+	return firing, resolved
+}
+
+func MaxSeverity(events []Event) Severity {
+	severity := Severity("")
+	for _, event := range events {
+		if event.Severity == SeverityCritical {
+			return SeverityCritical
+		}
+		if event.Severity == SeverityWarning {
+			severity = SeverityWarning
+		}
+	}
+	return severity
+}
+
+func pluralize(value float64, noun string) string {
+	if value == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%.0f %ss", value, noun)
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Minute).String()
+}
@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEventText(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	firing := Event{Kind: KindCPU, Severity: SeverityCritical, Transition: TransitionFiring, Value: 91, Threshold: 90, Window: 5 * time.Minute}
+	if got := firing.Text(); got != "CRIT CPU 91.0% >= 90.0% for 5m0s" {
+		t.Fatalf("unexpected firing text: %q", got)
+	}
+	resolved := Event{Kind: KindDisk, Resource: "/", Transition: TransitionResolved, Value: 40, Peak: 95.5, Since: start, At: start.Add(90 * time.Minute)}
+	if got := resolved.Text(); got != "Disk / 40.0% resolved after 1h30m0s (peak 95.5%)" {
+		t.Fatalf("unexpected resolved text: %q", got)
+	}
+	escalated := Event{Kind: KindMem, Severity: SeverityCritical, Previous: SeverityWarning, Transition: TransitionEscalated, Value: 95, Threshold: 90, Window: time.Minute}
+	if got := escalated.Text(); got != "CRIT Memory 95.0% >= 90.0% for 1m0s (escalated from WARN)" {
+		t.Fatalf("unexpected escalated text: %q", got)
+	}
+}
+
+func TestMaxSeverity(t *testing.T) {
+	if got := MaxSeverity([]Event{{Severity: SeverityWarning}}); got != SeverityWarning {
+		t.Fatalf("expected warning, got %q", got)
+	}
+	if got := MaxSeverity([]Event{{Severity: SeverityWarning}, {Severity: SeverityCritical}}); got != SeverityCritical {
+		t.Fatalf("expected critical, got %q", got)
+	}
+}
+
+func TestEventLabel(t *testing.T) {
+	if got := (Event{Kind: KindMem}).Label(); got != "Memory" {
+		t.Fatalf("unexpected label: %q", got)
+	}
+	if got := (Event{Kind: KindDisk, Resource: "/var"}).Label(); got != "Disk /var" {
+		t.Fatalf("unexpected label: %q", got)
+	}
+}
+
+func TestEventMarshalLogObject(t *testing.T) {
+	event := Event{Kind: KindDisk, Resource: "/data", Severity: SeverityCritical, Transition: TransitionFiring, Value: 95, Threshold: 90}
+	enc := zapcore.NewMapObjectEncoder()
+	if err := event.MarshalLogObject(enc); err != nil {
+		t.Fatalf("expected marshal to succeed, got %v", err)
+	}
+	if enc.Fields["kind"] != "disk" || enc.Fields["resource"] != "/data" {
+		t.Fatalf("unexpected fields: %#v", enc.Fields)
+	}
+	if enc.Fields["transition"] != "firing" || enc.Fields["severity"] != "critical" {
+		t.Fatalf("unexpected fields: %#v", enc.Fields)
+	}
+	if _, ok := enc.Fields["duration"]; ok {
+		t.Fatalf("did not expect duration on firing event")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	firing, resolved := Split([]Event{
+		{Kind: KindCPU, Transition: TransitionFiring},
+		{Kind: KindMem, Transition: TransitionResolved},
+	})
+	if len(firing) != 1 || firing[0].Kind != KindCPU {
+		t.Fatalf("unexpected firing events: %#v", firing)
+	}
+	if len(resolved) != 1 || resolved[0].Kind != KindMem {
+		t.Fatalf("unexpected resolved events: %#v", resolved)
+	}
+}
+
+func TestEventTextBytesBelow(t *testing.T) {
+	firing := Event{Kind: KindDiskFree, Resource: "/var", Severity: SeverityCritical, Transition: TransitionFiring, Value: 8.5 * 1024 * 1024 * 1024, Threshold: 10 * 1024 * 1024 * 1024, Window: time.Minute}
+	if got := firing.Text(); got != "CRIT Disk free /var 8.5GiB < 10.0GiB for 1m0s" {
+		t.Fatalf("unexpected firing text: %q", got)
+	}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	resolved := Event{Kind: KindDiskFree, Resource: "/var", Transition: TransitionResolved, Value: 20 * 1024 * 1024 * 1024, Peak: 512 * 1024 * 1024, Since: start, At: start.Add(time.Hour)}
+	if got := resolved.Text(); got != "Disk free /var 20.0GiB resolved after 1h0m0s (low 512.0MiB)" {
+		t.Fatalf("unexpected resolved text: %q", got)
+	}
+}
+
+func TestEventTextDiskFill(t *testing.T) {
+	firing := Event{Kind: KindDiskFill, Resource: "/var", Severity: SeverityWarning, Transition: TransitionFiring, Value: (5*time.Hour + 50*time.Minute).Seconds(), Threshold: (6 * time.Hour).Seconds(), Rate: 1024 * 1024 * 1024 / 3600.0}
+	if got := firing.Text(); got != "WARN Disk /var full in 5h50m0s < 6h0m0s at +1.0GiB/h for 0s" {
+		t.Fatalf("unexpected disk fill text: %q", got)
+	}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	resolved := Event{Kind: KindDiskFill, Resource: "/var", Transition: TransitionResolved, Value: noForecast, Peak: (3 * time.Hour).Seconds(), Since: start, At: start.Add(2 * time.Hour)}
+	if got := resolved.Text(); got != "Disk fill /var resolved after 2h0m0s (ETA never, low 3h0m0s)" {
+		t.Fatalf("unexpected resolved text: %q", got)
+	}
+}
+
+func TestEventTextRepeat(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	event := Event{Kind: KindDisk, Resource: "/", Severity: SeverityCritical, Transition: TransitionRepeat, Value: 95, Threshold: 90, Window: 5 * time.Minute, Since: start, At: start.Add(72 * time.Hour)}
+	if got := event.Text(); got != "CRIT Disk / 95.0% >= 90.0% for 5m0s (still firing for 72h0m0s)" {
+		t.Fatalf("unexpected repeat text: %q", got)
+	}
+}
+
+func TestEventTextLoad(t *testing.T) {
+	event := Event{Kind: KindLoad, Severity: SeverityWarning, Transition: TransitionFiring, Value: 1.25, Threshold: 1, Window: 5 * time.Minute}
+	if got := event.Text(); got != "WARN Load/core 1.25 >= 1.00 for 5m0s" {
+		t.Fatalf("unexpected load text: %q", got)
+	}
+}
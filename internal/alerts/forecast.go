@@ -0,0 +1,72 @@
+package alerts
+
+import (
+	"math"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+const noForecast = math.MaxFloat64
+
+type DiskSample struct {
+	At        time.Time `json:"at"`
+	UsedBytes uint64    `json:"used_bytes"`
+}
+
+func (c *checker) forecastDisk(d monitor.DiskUsage, rule Rule, lookback time.Duration) {
+	if !rule.Enabled() || lookback <= 0 {
+		return
+	}
+	history := append(c.state.DiskHistory[d.Mountpoint], DiskSample{At: c.now, UsedBytes: d.UsedBytes})
+	cutoff := c.now.Add(-lookback)
+	start := 0
+	for start < len(history) && history[start].At.Before(cutoff) {
+		start++
+	}
+	history = history[start:]
+	c.state.DiskHistory[d.Mountpoint] = history
+
+	eta := noForecast
+	rate, ok := growthRate(history, lookback)
+	if ok && rate > 0 {
+		eta = float64(d.FreeBytes) / rate
+	}
+	if rate < 0 {
+		rate = 0
+	}
+	c.evalEvent(Event{Kind: KindDiskFill, Resource: d.Mountpoint, Value: eta, Rate: rate}, rule)
+}
+
+func growthRate(history []DiskSample, lookback time.Duration) (float64, bool) {
+	if len(history) < 3 {
+		return 0, false
+	}
+	first := history[0].At
+	if history[len(history)-1].At.Sub(first) < lookback/2 {
+		return 0, false
+	}
+	n := float64(len(history))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range history {
+		x := sample.At.Sub(first).Seconds()
+		y := float64(sample.UsedBytes)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denom, true
+}
+
+func (c *checker) pruneHistory() {
+	for mount := range c.state.DiskHistory {
+		if _, ok := c.seen[stateKey(KindDiskFill, mount)]; !ok {
+			delete(c.state.DiskHistory, mount)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package alerts
+
+import (
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+type Silence struct {
+	Kind     string
+	Resource string
+	Start    time.Time
+	End      time.Time
+	Schedule cron.Schedule
+	Duration time.Duration
+}
+
+func (s Silence) Active(now time.Time) bool {
+	if s.Schedule != nil {
+		if s.Duration <= 0 {
+			return false
+		}
+		start := s.Schedule.Next(now.UTC().Add(-s.Duration))
+		return !start.IsZero() && !start.After(now)
+	}
+	return !now.Before(s.Start) && now.Before(s.End)
+}
+
+func (s Silence) Matches(kind Kind, resource string) bool {
+	if s.Kind != "" && s.Kind != "*" && string(kind) != s.Kind && !strings.HasPrefix(string(kind), s.Kind+"_") {
+		return false
+	}
+	if s.Resource == "" || s.Resource == "*" {
+		return true
+	}
+	_, ok := monitor.MatchMountPattern(s.Resource, resource)
+	return ok
+}
@@ -0,0 +1,157 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+func TestSilenceActiveAbsolute(t *testing.T) {
+	start := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	silence := Silence{Start: start, End: start.Add(time.Hour)}
+	if silence.Active(start.Add(-time.Second)) {
+		t.Fatalf("expected inactive before start")
+	}
+	if !silence.Active(start) || !silence.Active(start.Add(59*time.Minute)) {
+		t.Fatalf("expected active inside window")
+	}
+	if silence.Active(start.Add(time.Hour)) {
+		t.Fatalf("expected inactive at end")
+	}
+}
+
+func TestSilenceActiveRecurring(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 3 * * 0")
+	if err != nil {
+		t.Fatalf("parse schedule: %v", err)
+	}
+	silence := Silence{Schedule: schedule, Duration: 2 * time.Hour}
+	sunday := time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		at     time.Time
+		active bool
+	}{
+		{sunday.Add(2*time.Hour + 59*time.Minute), false},
+		{sunday.Add(3 * time.Hour), true},
+		{sunday.Add(4*time.Hour + 59*time.Minute), true},
+		{sunday.Add(5 * time.Hour), false},
+		{sunday.Add(24*time.Hour + 3*time.Hour), false},
+	}
+	for _, tc := range cases {
+		if got := silence.Active(tc.at); got != tc.active {
+			t.Fatalf("at %s expected active=%v, got %v", tc.at, tc.active, got)
+		}
+	}
+}
+
+func TestSilenceMatches(t *testing.T) {
+	cases := []struct {
+		silence  Silence
+		kind     Kind
+		resource string
+		want     bool
+	}{
+		{Silence{}, KindCPU, "", true},
+		{Silence{Kind: "*"}, KindDisk, "/", true},
+		{Silence{Kind: "cpu"}, KindCPU, "", true},
+		{Silence{Kind: "cpu"}, KindMem, "", false},
+		{Silence{Kind: "disk"}, KindDiskFree, "/data", true},
+		{Silence{Kind: "disk_free"}, KindDisk, "/data", false},
+		{Silence{Kind: "disk", Resource: "/mnt/*"}, KindDisk, "/mnt/backup", true},
+		{Silence{Kind: "disk", Resource: "/mnt/*"}, KindDisk, "/", false},
+		{Silence{Resource: "/"}, KindDiskFill, "/", true},
+	}
+	for _, tc := range cases {
+		if got := tc.silence.Matches(tc.kind, tc.resource); got != tc.want {
+			t.Fatalf("%#v matching %s %q: expected %v, got %v", tc.silence, tc.kind, tc.resource, tc.want, got)
+		}
+	}
+}
+
+func TestSilencedFiringDeliveredAfterWindow(t *testing.T) {
+	start := time.Now()
+	state := NewState()
+	cfg := Thresholds{
+		CPU:      Rule{Crit: Level{Threshold: 90}},
+		Silences: []Silence{{Kind: "cpu", Start: start, End: start.Add(time.Hour)}},
+	}
+	metrics := monitor.Metrics{CPUPercent: 95}
+
+	events := Check(metrics, cfg, state, start)
+	if len(events) != 1 || !events[0].Silenced || events[0].Transition != TransitionFiring {
+		t.Fatalf("expected silenced firing event, got %#v", events)
+	}
+	if firing, resolved := Split(events); len(firing) != 0 || len(resolved) != 0 {
+		t.Fatalf("expected silenced events to be split out")
+	}
+	if events := Check(metrics, cfg, state, start.Add(30*time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no event while silenced, got %#v", events)
+	}
+	events = Check(metrics, cfg, state, start.Add(time.Hour))
+	if len(events) != 1 || events[0].Silenced || events[0].Transition != TransitionFiring {
+		t.Fatalf("expected firing delivered after silence, got %#v", events)
+	}
+	if !events[0].Since.Equal(start) {
+		t.Fatalf("expected since to cover the silenced period")
+	}
+}
+
+func TestSilencedResolveDeliveredAfterWindow(t *testing.T) {
+	start := time.Now()
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 90}}}
+	Check(monitor.Metrics{CPUPercent: 95}, cfg, state, start)
+
+	cfg.Silences = []Silence{{Start: start.Add(time.Minute), End: start.Add(time.Hour)}}
+	events := Check(monitor.Metrics{CPUPercent: 99}, cfg, state, start.Add(time.Minute))
+	if len(events) != 0 {
+		t.Fatalf("expected no event while still firing, got %#v", events)
+	}
+	events = Check(monitor.Metrics{CPUPercent: 10}, cfg, state, start.Add(2*time.Minute))
+	if len(events) != 1 || !events[0].Silenced || events[0].Transition != TransitionResolved {
+		t.Fatalf("expected silenced resolve, got %#v", events)
+	}
+	events = Check(monitor.Metrics{CPUPercent: 10}, cfg, state, start.Add(time.Hour))
+	if len(events) != 1 || events[0].Silenced || events[0].Transition != TransitionResolved {
+		t.Fatalf("expected resolve delivered after silence, got %#v", events)
+	}
+	if events[0].Peak != 99 || events[0].Duration() != time.Hour {
+		t.Fatalf("expected peak and duration of the whole incident, got %#v", events[0])
+	}
+	if len(state.Metrics) != 0 {
+		t.Fatalf("expected state cleared after delivered resolve")
+	}
+}
+
+func TestSilenceSwallowsShortIncident(t *testing.T) {
+	start := time.Now()
+	state := NewState()
+	cfg := Thresholds{
+		CPU:      Rule{Crit: Level{Threshold: 90}},
+		Silences: []Silence{{Start: start, End: start.Add(time.Hour)}},
+	}
+	Check(monitor.Metrics{CPUPercent: 95}, cfg, state, start)
+	Check(monitor.Metrics{CPUPercent: 10}, cfg, state, start.Add(time.Minute))
+	if events := Check(monitor.Metrics{CPUPercent: 10}, cfg, state, start.Add(time.Hour)); len(events) != 0 {
+		t.Fatalf("expected nothing delivered for an incident inside the silence, got %#v", events)
+	}
+}
+
+func TestSilenceSuppressesRenotify(t *testing.T) {
+	start := time.Now()
+	state := NewState()
+	cfg := Thresholds{CPU: Rule{Crit: Level{Threshold: 90}, Renotify: time.Hour}}
+	metrics := monitor.Metrics{CPUPercent: 95}
+	Check(metrics, cfg, state, start)
+	cfg.Silences = []Silence{{Start: start, End: start.Add(3 * time.Hour)}}
+	if events := Check(metrics, cfg, state, start.Add(2*time.Hour)); len(events) != 0 {
+		t.Fatalf("expected no repeat while silenced, got %#v", events)
+	}
+	events := Check(metrics, cfg, state, start.Add(3*time.Hour))
+	if len(events) != 1 || events[0].Transition != TransitionRepeat {
+		t.Fatalf("expected repeat after silence, got %#v", events)
+	}
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"flag"
+	"fmt"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,18 +14,75 @@ import (
 type Config struct {
 	TelegramToken    string
 	TelegramChatID   string
+	TelegramRoute    NotifyRoute
+	Webhook          WebhookConfig
+	Email            EmailConfig
+	Slack            SlackConfig
+	Discord          DiscordConfig
 	SystemName       string
 	LogInterval      time.Duration
 	TelegramSchedule string
-	CPUThreshold     float64
-	CPUAlertWindow   time.Duration
-	MemThreshold     float64
-	MemAlertWindow   time.Duration
-	DiskThreshold    float64
-	DiskAlertWindow  time.Duration
+	CPU              AlertRule
+	Steal            AlertRule
+	IOWait           AlertRule
+	Mem              AlertRule
+	Disk             AlertRule
+	Swap             AlertRule
+	Load             AlertRule
+	DiskMounts       []MountRule
+	DiskFree         AlertRule
+	DiskFreeMounts   []MountRule
+	DiskFillLookback time.Duration
+	Inodes           AlertRule
+	InodeMounts      []MountRule
+	DiskFillWarn     time.Duration
+	DiskFillCrit     time.Duration
 	MountInclude     []string
 	MountExclude     []string
 	FstypeExclude    []string
+	IfaceInclude     []string
+	IfaceExclude     []string
+	DiskUtil         AlertRule
+	DiskLatency      AlertRule
+	Temp             AlertRule
+	TempInclude      []string
+	TempExclude      []string
+	SysfsRoot        string
+	ProcfsRoot       string
+	PressureCPU      AlertRule
+	PressureMemory   AlertRule
+	PressureIO       AlertRule
+	PressureWindow   int
+	OOMKillAlert     bool
+	Net              AlertRule
+	NetErrors        AlertRule
+	TopProcesses     int
+	TopReport        bool
+	Processes        []ProcessCheck
+	ProcessWindow    time.Duration
+	ProcessRenotify  time.Duration
+	StateDir         string
+	StateMaxAge      time.Duration
+	Silences         []Silence
+	SilenceErrors    []error
+	ProcessErrors    []error
+	MountRuleErrors  []error
+}
+
+type AlertRule struct {
+	WarnThreshold float64
+	WarnWindow    time.Duration
+	CritThreshold float64
+	CritWindow    time.Duration
+	WarnClear     float64
+	CritClear     float64
+	ClearWindow   time.Duration
+	Renotify      time.Duration
+}
+
+type MountRule struct {
+	Pattern string
+	Rule    AlertRule
 }
 
 func Load() Config {
@@ -39,53 +99,467 @@ func LoadFrom(fs *flag.FlagSet, getenv func(string) string, args []string) Confi
 
 	defaultLogInterval := envDuration(getenv, "INTERVAL", time.Minute)
 	defaultTelegramSchedule := envString(getenv, "TELEGRAM_SCHEDULE", "0 12 * * 0")
-	defaultCPU := envFloat(getenv, "CPU_THRESHOLD", 90)
-	defaultCPUWindow := envDuration(getenv, "CPU_ALERT_WINDOW", 5*time.Minute)
-	defaultMem := envFloat(getenv, "MEM_THRESHOLD", 90)
-	defaultMemWindow := envDuration(getenv, "MEM_ALERT_WINDOW", 5*time.Minute)
-	defaultDisk := envFloat(getenv, "DISK_THRESHOLD", 90)
-	defaultDiskWindow := envDuration(getenv, "DISK_ALERT_WINDOW", 5*time.Minute)
 	defaultToken := envString(getenv, "TELEGRAM_BOT_TOKEN", "")
 	defaultChat := envString(getenv, "TELEGRAM_CHAT_ID", "")
 	defaultSystemName := envString(getenv, "SYSTEM_NAME", "")
+	defaultWebhookURL := envString(getenv, "WEBHOOK_URL", "")
+	defaultWebhookHeaders := envString(getenv, "WEBHOOK_HEADERS", "")
+	defaultWebhookSecret := envString(getenv, "WEBHOOK_SECRET", "")
+	defaultWebhookTimeout := envDuration(getenv, "WEBHOOK_TIMEOUT", 10*time.Second)
+	defaultWebhookRetries := envInt(getenv, "WEBHOOK_RETRIES", 2)
+	defaultSMTPHost := envString(getenv, "SMTP_HOST", "")
+	defaultSMTPPort := envInt(getenv, "SMTP_PORT", 587)
+	defaultSMTPUsername := envString(getenv, "SMTP_USERNAME", "")
+	defaultSMTPPassword := envString(getenv, "SMTP_PASSWORD", "")
+	defaultSMTPFrom := envString(getenv, "SMTP_FROM", "")
+	defaultSMTPTo := envString(getenv, "SMTP_TO", "")
+	defaultSMTPSecurity := envString(getenv, "SMTP_SECURITY", "starttls")
+	defaultSMTPAuth := envString(getenv, "SMTP_AUTH", "plain")
+	defaultSMTPTimeout := envDuration(getenv, "SMTP_TIMEOUT", 10*time.Second)
+	defaultSlackURL := envString(getenv, "SLACK_WEBHOOK_URL", "")
+	defaultSlackChannel := envString(getenv, "SLACK_CHANNEL", "")
+	defaultSlackUsername := envString(getenv, "SLACK_USERNAME", "")
+	defaultSlackTimeout := envDuration(getenv, "SLACK_TIMEOUT", 10*time.Second)
+	defaultDiscordURL := envString(getenv, "DISCORD_WEBHOOK_URL", "")
+	defaultDiscordUsername := envString(getenv, "DISCORD_USERNAME", "")
+	defaultDiscordTimeout := envDuration(getenv, "DISCORD_TIMEOUT", 10*time.Second)
+	defaultDiscordRetries := envInt(getenv, "DISCORD_RETRIES", 2)
+	defaultDiskMounts := envString(getenv, "DISK_MOUNT_THRESHOLDS", "")
+	defaultDiskMinFree := envSize(getenv, "DISK_MIN_FREE", 0)
+	defaultDiskWarnMinFree := envSize(getenv, "DISK_WARN_MIN_FREE", 0)
+	defaultDiskCritMinFree := envSize(getenv, "DISK_CRIT_MIN_FREE", defaultDiskMinFree)
+	defaultDiskWarnMinFreeClear := envSize(getenv, "DISK_WARN_MIN_FREE_CLEAR", 0)
+	defaultDiskCritMinFreeClear := envSize(getenv, "DISK_CRIT_MIN_FREE_CLEAR", 0)
+	defaultDiskMountMinFree := envString(getenv, "DISK_MOUNT_MIN_FREE", "")
+	defaultInodeMounts := envString(getenv, "DISK_MOUNT_INODE_THRESHOLDS", "")
+	defaultDiskFillLookback := envDuration(getenv, "DISK_FILL_LOOKBACK", time.Hour)
+	defaultDiskFillWarn := envDuration(getenv, "DISK_FILL_WARN_HORIZON", 0)
+	defaultDiskFillCrit := envDuration(getenv, "DISK_FILL_CRIT_HORIZON", 0)
 	defaultMountInclude := envString(getenv, "MOUNT_INCLUDE", "")
 	defaultMountExclude := envString(getenv, "MOUNT_EXCLUDE", "/dev*,/proc*,/sys*,/run*")
+	defaultIfaceInclude := envString(getenv, "IFACE_INCLUDE", "")
+	defaultIfaceExclude := envString(getenv, "IFACE_EXCLUDE", "lo,veth*")
+	defaultTempInclude := envString(getenv, "TEMP_INCLUDE", "")
+	defaultTempExclude := envString(getenv, "TEMP_EXCLUDE", "")
+	defaultSysfsRoot := envString(getenv, "SYSFS_ROOT", "/sys")
+	defaultProcfsRoot := envString(getenv, "PROCFS_ROOT", "/proc")
+	defaultOOMKillAlert := envBool(getenv, "OOM_KILL_ALERT", false)
+	defaultPressureWindow := envInt(getenv, "PSI_AVG", 60)
+	defaultTopProcesses := envInt(getenv, "TOP_PROCESSES", 5)
+	defaultTopReport := envBool(getenv, "TOP_PROCESSES_REPORT", false)
+	defaultProcesses := envString(getenv, "PROCESS_CHECKS", "")
+	defaultProcessWindow := envDuration(getenv, "PROCESS_ALERT_WINDOW", 0)
+	defaultStateDir := envString(getenv, "STATE_DIR", "")
+	defaultStateMaxAge := envDuration(getenv, "STATE_MAX_AGE", time.Hour)
+	defaultSilences := envString(getenv, "SILENCES", "")
 	defaultFstypeExclude := envString(getenv, "FSTYPE_EXCLUDE", "tmpfs,devtmpfs,overlay,proc,sysfs,devpts,cgroup,cgroup2,pstore,securityfs,debugfs,tracefs,configfs,ramfs,hugetlbfs,mqueue,autofs,binfmt_misc,fusectl,efivarfs")
 
 	logInterval := fs.Duration("interval", defaultLogInterval, "metrics log interval")
 	telegramSchedule := fs.String("telegram-schedule", defaultTelegramSchedule, "telegram metrics cron schedule (UTC)")
-	cpuThreshold := fs.Float64("cpu-threshold", defaultCPU, "cpu usage percent threshold")
-	cpuAlertWindow := fs.Duration("cpu-alert-window", defaultCPUWindow, "cpu threshold window before alert")
-	memThreshold := fs.Float64("mem-threshold", defaultMem, "memory usage percent threshold")
-	memAlertWindow := fs.Duration("mem-alert-window", defaultMemWindow, "memory threshold window before alert")
-	diskThreshold := fs.Float64("disk-threshold", defaultDisk, "disk usage percent threshold")
-	diskAlertWindow := fs.Duration("disk-alert-window", defaultDiskWindow, "disk threshold window before alert")
+	defaultRenotify := envDuration(getenv, "ALERT_RENOTIFY", 0)
+	renotify := fs.Duration("alert-renotify", defaultRenotify, "re-send alerts that are still firing after this duration (0 disables)")
+	cpuRule := registerRule(fs, getenv, "CPU", "cpu", "cpu usage", usageDefaults)
+	memRule := registerRule(fs, getenv, "MEM", "mem", "memory usage", usageDefaults)
+	diskRule := registerRule(fs, getenv, "DISK", "disk", "disk usage", usageDefaults)
+	stealRule := registerRule(fs, getenv, "CPU_STEAL", "cpu-steal", "cpu steal time", ruleDefaults{unit: "percent", percent: true})
+	iowaitRule := registerRule(fs, getenv, "CPU_IOWAIT", "cpu-iowait", "cpu iowait time", ruleDefaults{unit: "percent", percent: true})
+	swapRule := registerRule(fs, getenv, "SWAP", "swap", "swap usage", ruleDefaults{unit: "percent", percent: true})
+	loadRule := registerRule(fs, getenv, "LOAD", "load", "1-minute load average", ruleDefaults{unit: "per core"})
+	inodeRule := registerRule(fs, getenv, "INODE", "inode", "inode usage", ruleDefaults{unit: "percent", percent: true})
+	diskUtilRule := registerRule(fs, getenv, "DISK_UTIL", "disk-util", "disk device busy time", ruleDefaults{unit: "percent", percent: true})
+	diskLatencyRule := registerRule(fs, getenv, "DISK_LATENCY", "disk-latency", "disk device average wait", ruleDefaults{unit: "ms"})
+	tempRule := registerRule(fs, getenv, "TEMP", "temp", "sensor temperature", ruleDefaults{unit: "°C", legacy: true})
+	netRule := registerRule(fs, getenv, "NET", "net", "network rx or tx throughput", ruleDefaults{unit: "Mbit/s"})
+	pressureCPURule := registerRule(fs, getenv, "PSI_CPU", "psi-cpu", "cpu pressure stall (some)", ruleDefaults{unit: "percent", percent: true})
+	pressureMemoryRule := registerRule(fs, getenv, "PSI_MEMORY", "psi-memory", "memory pressure stall (some)", ruleDefaults{unit: "percent", percent: true})
+	pressureIORule := registerRule(fs, getenv, "PSI_IO", "psi-io", "io pressure stall (some)", ruleDefaults{unit: "percent", percent: true})
+	netErrorsRule := registerRule(fs, getenv, "NET_ERRORS", "net-errors", "new network interface errors", ruleDefaults{unit: "count per check"})
 	telegramToken := fs.String("telegram-token", defaultToken, "telegram bot token")
 	telegramChatID := fs.String("telegram-chat-id", defaultChat, "telegram chat id")
+	telegramRoute := registerRoute(fs, getenv, "TELEGRAM", "telegram", "telegram")
+	webhookURL := fs.String("webhook-url", defaultWebhookURL, "url receiving JSON alerts and reports (empty disables)")
+	webhookHeaders := fs.String("webhook-headers", defaultWebhookHeaders, "semicolon-separated extra webhook headers: Name: value")
+	webhookSecret := fs.String("webhook-secret", defaultWebhookSecret, "secret for the HMAC-SHA256 webhook signature header (empty disables)")
+	webhookTimeout := fs.Duration("webhook-timeout", defaultWebhookTimeout, "timeout per webhook request")
+	webhookRetries := fs.Int("webhook-retries", defaultWebhookRetries, "webhook retries after network errors, 429 and 5xx responses")
+	webhookRoute := registerRoute(fs, getenv, "WEBHOOK", "webhook", "the webhook")
+	smtpHost := fs.String("smtp-host", defaultSMTPHost, "smtp server host for email notifications (empty disables)")
+	smtpPort := fs.Int("smtp-port", defaultSMTPPort, "smtp server port")
+	smtpUsername := fs.String("smtp-username", defaultSMTPUsername, "smtp auth username (empty skips auth)")
+	smtpPassword := fs.String("smtp-password", defaultSMTPPassword, "smtp auth password")
+	smtpFrom := fs.String("smtp-from", defaultSMTPFrom, "email sender address")
+	smtpTo := fs.String("smtp-to", defaultSMTPTo, "comma-separated email recipients")
+	smtpSecurity := fs.String("smtp-security", defaultSMTPSecurity, "smtp connection security: starttls, tls or none")
+	smtpAuth := fs.String("smtp-auth", defaultSMTPAuth, "smtp auth mechanism: plain or login")
+	smtpTimeout := fs.Duration("smtp-timeout", defaultSMTPTimeout, "timeout per email delivery")
+	smtpRoute := registerRoute(fs, getenv, "SMTP", "smtp", "email")
+	slackURL := fs.String("slack-webhook-url", defaultSlackURL, "slack or mattermost incoming webhook url (empty disables)")
+	slackChannel := fs.String("slack-channel", defaultSlackChannel, "channel override for the slack webhook")
+	slackUsername := fs.String("slack-username", defaultSlackUsername, "username override for the slack webhook")
+	slackTimeout := fs.Duration("slack-timeout", defaultSlackTimeout, "timeout per slack request")
+	slackRoute := registerRoute(fs, getenv, "SLACK", "slack", "slack")
+	discordURL := fs.String("discord-webhook-url", defaultDiscordURL, "discord webhook url (empty disables)")
+	discordUsername := fs.String("discord-username", defaultDiscordUsername, "username override for the discord webhook")
+	discordTimeout := fs.Duration("discord-timeout", defaultDiscordTimeout, "timeout per discord request")
+	discordRetries := fs.Int("discord-retries", defaultDiscordRetries, "discord retries after 429 rate limit responses")
+	discordRoute := registerRoute(fs, getenv, "DISCORD", "discord", "discord")
 	systemName := fs.String("system-name", defaultSystemName, "custom system name")
+	diskMounts := fs.String("disk-mount-thresholds", defaultDiskMounts, "comma-separated per-mount disk thresholds: pattern=percent[:window][:warn|crit][:clear=percent]")
+	diskMinFree := sizeValue(defaultDiskMinFree)
+	fs.Var(&diskMinFree, "disk-min-free", "alias for -disk-crit-min-free")
+	diskWarnMinFree := sizeValue(defaultDiskWarnMinFree)
+	fs.Var(&diskWarnMinFree, "disk-warn-min-free", "disk free space warning threshold, e.g. 20GiB (0 disables)")
+	diskCritMinFree := sizeValue(defaultDiskCritMinFree)
+	fs.Var(&diskCritMinFree, "disk-crit-min-free", "disk free space critical threshold, e.g. 10GiB (0 disables)")
+	diskWarnMinFreeClear := sizeValue(defaultDiskWarnMinFreeClear)
+	fs.Var(&diskWarnMinFreeClear, "disk-warn-min-free-clear", "free space above which a disk free warning clears (0 uses the warning threshold)")
+	diskCritMinFreeClear := sizeValue(defaultDiskCritMinFreeClear)
+	fs.Var(&diskCritMinFreeClear, "disk-crit-min-free-clear", "free space above which a disk free alert clears (0 uses the critical threshold)")
+	diskMountMinFree := fs.String("disk-mount-min-free", defaultDiskMountMinFree, "comma-separated per-mount free space thresholds: pattern=size[:window][:warn|crit][:clear=size]")
+	inodeMounts := fs.String("disk-mount-inode-thresholds", defaultInodeMounts, "comma-separated per-mount inode thresholds: pattern=percent[:window][:warn|crit][:clear=percent]")
+	diskFillLookback := fs.Duration("disk-fill-lookback", defaultDiskFillLookback, "disk usage history used to forecast time until full")
+	diskFillWarn := fs.Duration("disk-fill-warn-horizon", defaultDiskFillWarn, "warn when a disk is forecast to fill within this duration (0 disables)")
+	diskFillCrit := fs.Duration("disk-fill-crit-horizon", defaultDiskFillCrit, "alert when a disk is forecast to fill within this duration (0 disables)")
 	mountInclude := fs.String("mount-include", defaultMountInclude, "comma-separated mountpoints to include (overrides exclude)")
 	mountExclude := fs.String("mount-exclude", defaultMountExclude, "comma-separated mountpoints to exclude (supports * suffix)")
+	ifaceInclude := fs.String("iface-include", defaultIfaceInclude, "comma-separated network interfaces to include (overrides exclude, supports * suffix)")
+	ifaceExclude := fs.String("iface-exclude", defaultIfaceExclude, "comma-separated network interfaces to exclude (supports * suffix)")
+	tempInclude := fs.String("temp-include", defaultTempInclude, "comma-separated temperature sensors to include, e.g. coretemp/* (overrides exclude)")
+	tempExclude := fs.String("temp-exclude", defaultTempExclude, "comma-separated temperature sensors to exclude (supports * suffix)")
+	sysfsRoot := fs.String("sysfs-root", defaultSysfsRoot, "sysfs mount used for hardware sensors")
+	procfsRoot := fs.String("procfs-root", defaultProcfsRoot, "procfs mount used for pressure stall information")
+	oomKillAlert := fs.Bool("oom-kill-alert", defaultOOMKillAlert, "alert immediately when the kernel OOM killer ran since the previous check")
+	pressureWindow := fs.Int("psi-avg", defaultPressureWindow, "pressure stall average used for alerts in seconds: 10, 60 or 300")
+	topProcesses := fs.Int("top-processes", defaultTopProcesses, "number of top cpu and memory processes attached to cpu, memory and load alerts (0 disables)")
+	topReport := fs.Bool("top-processes-report", defaultTopReport, "also attach top processes to the scheduled telegram report")
+	processes := fs.String("process-checks", defaultProcesses, "semicolon-separated process checks: name=name:proc|cmdline:regex|pidfile:path[|min=N][|max=N][|restart]")
+	processWindow := fs.Duration("process-alert-window", defaultProcessWindow, "how long a process check must fail before alerting")
 	fstypeExclude := fs.String("fstype-exclude", defaultFstypeExclude, "comma-separated filesystem types to exclude")
 
+	stateDir := fs.String("state-dir", defaultStateDir, "directory for the persisted alert state (empty disables)")
+	stateMaxAge := fs.Duration("state-max-age", defaultStateMaxAge, "discard pending alert timers from a state file older than this")
+	silences := fs.String("silences", defaultSilences, "semicolon-separated alert silences: cron|duration[|kind[:mount]] or start/end[|kind[:mount]] (RFC3339)")
+
 	if !fs.Parsed() {
 		_ = fs.Parse(args)
 	}
 
+	disk := diskRule.rule(fs, *renotify)
+	diskMountRules, diskMountErrors := parseMountRules("DISK_MOUNT_THRESHOLDS", *diskMounts, disk, parsePercent)
+	diskFreeMounts, diskFreeMountErrors := parseMountRules("DISK_MOUNT_MIN_FREE", *diskMountMinFree, disk, parseSizeValue)
+	if set := visitedFlags(fs); set["disk-min-free"] && !set["disk-crit-min-free"] {
+		diskCritMinFree = diskMinFree
+	}
+	silenceList, silenceErrors := parseSilences(*silences)
+	processChecks, processErrors := parseProcessChecks(*processes)
+	inodes := inodeRule.rule(fs, *renotify)
+	inodeMountRules, inodeMountErrors := parseMountRules("DISK_MOUNT_INODE_THRESHOLDS", *inodeMounts, inodes, parsePercent)
+
 	return Config{
-		TelegramToken:    *telegramToken,
-		TelegramChatID:   *telegramChatID,
+		TelegramToken:  *telegramToken,
+		TelegramChatID: *telegramChatID,
+		TelegramRoute:  telegramRoute.route(),
+		Webhook: WebhookConfig{
+			URL:     strings.TrimSpace(*webhookURL),
+			Headers: parseHeaders(*webhookHeaders),
+			Secret:  *webhookSecret,
+			Timeout: *webhookTimeout,
+			Retries: max(*webhookRetries, 0),
+			Route:   webhookRoute.route(),
+		},
+		Email: EmailConfig{
+			Host:     strings.TrimSpace(*smtpHost),
+			Port:     *smtpPort,
+			Username: *smtpUsername,
+			Password: *smtpPassword,
+			From:     strings.TrimSpace(*smtpFrom),
+			To:       parseList(*smtpTo),
+			Security: parseEmailSecurity(*smtpSecurity),
+			Auth:     parseEmailAuth(*smtpAuth),
+			Timeout:  *smtpTimeout,
+			Route:    smtpRoute.route(),
+		},
+		Slack: SlackConfig{
+			URL:      strings.TrimSpace(*slackURL),
+			Channel:  strings.TrimSpace(*slackChannel),
+			Username: strings.TrimSpace(*slackUsername),
+			Timeout:  *slackTimeout,
+			Route:    slackRoute.route(),
+		},
+		Discord: DiscordConfig{
+			URL:      strings.TrimSpace(*discordURL),
+			Username: strings.TrimSpace(*discordUsername),
+			Timeout:  *discordTimeout,
+			Retries:  max(*discordRetries, 0),
+			Route:    discordRoute.route(),
+		},
 		SystemName:       strings.TrimSpace(*systemName),
 		LogInterval:      *logInterval,
 		TelegramSchedule: strings.TrimSpace(*telegramSchedule),
-		CPUThreshold:     clampPercent(*cpuThreshold),
-		CPUAlertWindow:   *cpuAlertWindow,
-		MemThreshold:     clampPercent(*memThreshold),
-		MemAlertWindow:   *memAlertWindow,
-		DiskThreshold:    clampPercent(*diskThreshold),
-		DiskAlertWindow:  *diskAlertWindow,
+		CPU:              cpuRule.rule(fs, *renotify),
+		Steal:            stealRule.rule(fs, *renotify),
+		IOWait:           iowaitRule.rule(fs, *renotify),
+		Mem:              memRule.rule(fs, *renotify),
+		Disk:             disk,
+		Swap:             swapRule.rule(fs, *renotify),
+		Load:             loadRule.rule(fs, *renotify),
+		DiskMounts:       diskMountRules,
+		DiskFree: AlertRule{
+			WarnThreshold: float64(diskWarnMinFree),
+			WarnWindow:    disk.WarnWindow,
+			CritThreshold: float64(diskCritMinFree),
+			CritWindow:    disk.CritWindow,
+			WarnClear:     float64(diskWarnMinFreeClear),
+			CritClear:     float64(diskCritMinFreeClear),
+			ClearWindow:   disk.ClearWindow,
+			Renotify:      disk.Renotify,
+		},
+		DiskFreeMounts:   diskFreeMounts,
+		DiskFillLookback: *diskFillLookback,
+		Inodes:           inodes,
+		InodeMounts:      inodeMountRules,
+		DiskFillWarn:     *diskFillWarn,
+		DiskFillCrit:     *diskFillCrit,
 		MountInclude:     parseList(*mountInclude),
 		MountExclude:     parseList(*mountExclude),
 		FstypeExclude:    parseListLower(*fstypeExclude),
+		IfaceInclude:     parseList(*ifaceInclude),
+		IfaceExclude:     parseList(*ifaceExclude),
+		DiskUtil:         diskUtilRule.rule(fs, *renotify),
+		DiskLatency:      diskLatencyRule.rule(fs, *renotify),
+		Temp:             tempRule.rule(fs, *renotify),
+		TempInclude:      parseList(*tempInclude),
+		TempExclude:      parseList(*tempExclude),
+		SysfsRoot:        strings.TrimSpace(*sysfsRoot),
+		ProcfsRoot:       strings.TrimSpace(*procfsRoot),
+		PressureCPU:      pressureCPURule.rule(fs, *renotify),
+		PressureMemory:   pressureMemoryRule.rule(fs, *renotify),
+		PressureIO:       pressureIORule.rule(fs, *renotify),
+		PressureWindow:   pressureAvgWindow(*pressureWindow),
+		OOMKillAlert:     *oomKillAlert,
+		Net:              netRule.rule(fs, *renotify),
+		NetErrors:        netErrorsRule.rule(fs, *renotify),
+		TopProcesses:     max(*topProcesses, 0),
+		TopReport:        *topReport,
+		Processes:        processChecks,
+		ProcessWindow:    *processWindow,
+		ProcessRenotify:  *renotify,
+		StateDir:         strings.TrimSpace(*stateDir),
+		StateMaxAge:      *stateMaxAge,
+		Silences:         silenceList,
+		SilenceErrors:    silenceErrors,
+		ProcessErrors:    processErrors,
+		MountRuleErrors:  slices.Concat(diskMountErrors, diskFreeMountErrors, inodeMountErrors),
+	}
+}
+
+type ruleFlags struct {
+	flagPrefix      string
+	percent         bool
+	warnThreshold   *float64
+	warnWindow      *time.Duration
+	critThreshold   *float64
+	critWindow      *time.Duration
+	legacyThreshold *float64
+	legacyWindow    *time.Duration
+	warnClear       *float64
+	critClear       *float64
+	clearWindow     *time.Duration
+	renotify        *time.Duration
+	renotifyFromEnv bool
+}
+
+type ruleDefaults struct {
+	unit    string
+	warn    float64
+	crit    float64
+	percent bool
+	legacy  bool
+}
+
+var usageDefaults = ruleDefaults{unit: "percent", crit: 90, percent: true, legacy: true}
+
+func registerRule(fs *flag.FlagSet, getenv func(string) string, envPrefix string, flagPrefix string, label string, defaults ruleDefaults) *ruleFlags {
+	defaultLegacy := envFloat(getenv, envPrefix+"_THRESHOLD", defaults.crit)
+	defaultLegacyWindow := envDuration(getenv, envPrefix+"_ALERT_WINDOW", 5*time.Minute)
+	if !defaults.legacy {
+		defaultLegacy = defaults.crit
+		defaultLegacyWindow = 5 * time.Minute
+	}
+	defaultWarn := envFloat(getenv, envPrefix+"_WARN_THRESHOLD", defaults.warn)
+	defaultWarnWindow := envDuration(getenv, envPrefix+"_WARN_WINDOW", defaultLegacyWindow)
+	defaultCrit := envFloat(getenv, envPrefix+"_CRIT_THRESHOLD", defaultLegacy)
+	defaultCritWindow := envDuration(getenv, envPrefix+"_CRIT_WINDOW", defaultLegacyWindow)
+	defaultWarnClear := envFloat(getenv, envPrefix+"_WARN_CLEAR", 0)
+	defaultCritClear := envFloat(getenv, envPrefix+"_CRIT_CLEAR", 0)
+	defaultClearWindow := envDuration(getenv, envPrefix+"_CLEAR_WINDOW", 0)
+	defaultRenotify := envDuration(getenv, envPrefix+"_RENOTIFY", 0)
+
+	flags := &ruleFlags{
+		flagPrefix:      flagPrefix,
+		percent:         defaults.percent,
+		warnThreshold:   fs.Float64(flagPrefix+"-warn-threshold", defaultWarn, label+" "+defaults.unit+" warning threshold (0 disables)"),
+		warnWindow:      fs.Duration(flagPrefix+"-warn-window", defaultWarnWindow, label+" warning window before alert"),
+		critThreshold:   fs.Float64(flagPrefix+"-crit-threshold", defaultCrit, label+" "+defaults.unit+" critical threshold (0 disables)"),
+		critWindow:      fs.Duration(flagPrefix+"-crit-window", defaultCritWindow, label+" critical window before alert"),
+		warnClear:       fs.Float64(flagPrefix+"-warn-clear", defaultWarnClear, label+" "+defaults.unit+" below which a warning clears (0 uses the warning threshold)"),
+		critClear:       fs.Float64(flagPrefix+"-crit-clear", defaultCritClear, label+" "+defaults.unit+" below which a critical alert clears (0 uses the critical threshold)"),
+		clearWindow:     fs.Duration(flagPrefix+"-clear-window", defaultClearWindow, label+" duration below the clear level before an alert resolves"),
+		renotify:        fs.Duration(flagPrefix+"-renotify", defaultRenotify, label+" re-notify interval (defaults to -alert-renotify)"),
+		renotifyFromEnv: getenv(envPrefix+"_RENOTIFY") != "",
+	}
+	if defaults.legacy {
+		flags.legacyThreshold = fs.Float64(flagPrefix+"-threshold", defaultLegacy, "alias for -"+flagPrefix+"-crit-threshold")
+		flags.legacyWindow = fs.Duration(flagPrefix+"-alert-window", defaultLegacyWindow, "alias for -"+flagPrefix+"-warn-window and -"+flagPrefix+"-crit-window")
+	}
+	return flags
+}
+
+func (r *ruleFlags) rule(fs *flag.FlagSet, renotify time.Duration) AlertRule {
+	set := visitedFlags(fs)
+	rule := AlertRule{
+		WarnThreshold: *r.warnThreshold,
+		WarnWindow:    *r.warnWindow,
+		CritThreshold: *r.critThreshold,
+		CritWindow:    *r.critWindow,
+		WarnClear:     *r.warnClear,
+		CritClear:     *r.critClear,
+		ClearWindow:   *r.clearWindow,
+		Renotify:      *r.renotify,
+	}
+	if !set[r.flagPrefix+"-renotify"] && !r.renotifyFromEnv {
+		rule.Renotify = renotify
+	}
+	if r.legacyThreshold != nil && set[r.flagPrefix+"-threshold"] && !set[r.flagPrefix+"-crit-threshold"] {
+		rule.CritThreshold = *r.legacyThreshold
+	}
+	if r.legacyWindow != nil && set[r.flagPrefix+"-alert-window"] {
+		if !set[r.flagPrefix+"-warn-window"] {
+			rule.WarnWindow = *r.legacyWindow
+		}
+		if !set[r.flagPrefix+"-crit-window"] {
+			rule.CritWindow = *r.legacyWindow
+		}
+	}
+	if r.percent {
+		rule.WarnThreshold = clampPercent(rule.WarnThreshold)
+		rule.CritThreshold = clampPercent(rule.CritThreshold)
+		rule.WarnClear = clampPercent(rule.WarnClear)
+		rule.CritClear = clampPercent(rule.CritClear)
+	} else {
+		rule.WarnThreshold = math.Max(rule.WarnThreshold, 0)
+		rule.CritThreshold = math.Max(rule.CritThreshold, 0)
+		rule.WarnClear = math.Max(rule.WarnClear, 0)
+		rule.CritClear = math.Max(rule.CritClear, 0)
+	}
+	return rule
+}
+
+func visitedFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+func parseMountRules(setting, value string, defaults AlertRule, parseValue func(string) (float64, error)) ([]MountRule, []error) {
+	rules := []MountRule{}
+	errs := []error{}
+	index := make(map[string]int)
+	for _, item := range parseList(value) {
+		pattern, spec, ok := strings.Cut(item, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			errs = append(errs, fmt.Errorf("%s %q: expected pattern=threshold", setting, item))
+			continue
+		}
+		parts := strings.Split(spec, ":")
+		threshold, err := parseValue(strings.TrimSpace(parts[0]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: invalid threshold: %w", setting, item, err))
+			continue
+		}
+		critical := true
+		window := time.Duration(-1)
+		clear := 0.0
+		var partErr error
+		for _, part := range parts[1:] {
+			part = strings.ToLower(strings.TrimSpace(part))
+			switch {
+			case part == "warn" || part == "warning":
+				critical = false
+			case part == "crit" || part == "critical":
+				critical = true
+			case strings.HasPrefix(part, "clear="):
+				parsed, err := parseValue(strings.TrimPrefix(part, "clear="))
+				if err != nil {
+					partErr = fmt.Errorf("invalid clear level: %w", err)
+				}
+				clear = parsed
+			default:
+				parsed, err := time.ParseDuration(part)
+				if err != nil {
+					partErr = fmt.Errorf("invalid window or severity %q", part)
+				}
+				window = parsed
+			}
+		}
+		if partErr != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", setting, item, partErr))
+			continue
+		}
+
+		i, ok := index[pattern]
+		if !ok {
+			i = len(rules)
+			index[pattern] = i
+			rules = append(rules, MountRule{Pattern: pattern})
+		}
+		rule := &rules[i].Rule
+		rule.ClearWindow = defaults.ClearWindow
+		rule.Renotify = defaults.Renotify
+		if critical {
+			rule.CritThreshold = threshold
+			rule.CritClear = clear
+			rule.CritWindow = defaults.CritWindow
+			if window >= 0 {
+				rule.CritWindow = window
+			}
+		} else {
+			rule.WarnThreshold = threshold
+			rule.WarnClear = clear
+			rule.WarnWindow = defaults.WarnWindow
+			if window >= 0 {
+				rule.WarnWindow = window
+			}
+		}
+	}
+	return rules, errs
+}
+
+func parsePercent(value string) (float64, error) {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return clampPercent(parsed), nil
+}
+
+func parseSizeValue(value string) (float64, error) {
+	parsed, err := ParseSize(value)
+	if err != nil {
+		return 0, err
+	}
+	return float64(parsed), nil
+}
+
+func pressureAvgWindow(seconds int) int {
+	switch seconds {
+	case 10, 60, 300:
+		return seconds
+	default:
+		return 60
 	}
 }
 
@@ -108,6 +582,30 @@ func envFloat(getenv func(string) string, key string, def float64) float64 {
 	return parsed
 }
 
+func envInt(getenv func(string) string, key string, def int) int {
+	val := getenv(key)
+	if val == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(val)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func envBool(getenv func(string) string, key string, def bool) bool {
+	val := getenv(key)
+	if val == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
 func envDuration(getenv func(string) string, key string, def time.Duration) time.Duration {
 	val := getenv(key)
 	if val == "" {
@@ -120,6 +618,18 @@ func envDuration(getenv func(string) string, key string, def time.Duration) time
 	return parsed
 }
 
+func envSize(getenv func(string) string, key string, def uint64) uint64 {
+	val := getenv(key)
+	if val == "" {
+		return def
+	}
+	parsed, err := ParseSize(val)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
 func clampPercent(value float64) float64 {
 	if value < 0 {
 		return 0
@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"io"
+	"strings"
 	"testing"
 	"time"
 )
@@ -18,17 +19,20 @@ func TestLoadFromDefaults(t *testing.T) {
 	if cfg.TelegramSchedule != "0 12 * * 0" {
 		t.Fatalf("expected telegram schedule default, got %s", cfg.TelegramSchedule)
 	}
-	if cfg.CPUThreshold != 90 {
-		t.Fatalf("expected cpu threshold 90, got %.1f", cfg.CPUThreshold)
+	if cfg.CPU.CritThreshold != 90 {
+		t.Fatalf("expected cpu threshold 90, got %.1f", cfg.CPU.CritThreshold)
 	}
-	if cfg.CPUAlertWindow != 5*time.Minute {
-		t.Fatalf("expected cpu alert window 5m, got %s", cfg.CPUAlertWindow)
+	if cfg.CPU.WarnThreshold != 0 || cfg.Mem.WarnThreshold != 0 || cfg.Disk.WarnThreshold != 0 {
+		t.Fatalf("expected warn levels disabled by default, got %.1f/%.1f/%.1f", cfg.CPU.WarnThreshold, cfg.Mem.WarnThreshold, cfg.Disk.WarnThreshold)
 	}
-	if cfg.MemAlertWindow != 5*time.Minute {
-		t.Fatalf("expected mem alert window 5m, got %s", cfg.MemAlertWindow)
+	if cfg.CPU.CritWindow != 5*time.Minute || cfg.CPU.WarnWindow != 5*time.Minute {
+		t.Fatalf("expected cpu alert windows 5m, got %s/%s", cfg.CPU.WarnWindow, cfg.CPU.CritWindow)
 	}
-	if cfg.DiskAlertWindow != 5*time.Minute {
-		t.Fatalf("expected disk alert window 5m, got %s", cfg.DiskAlertWindow)
+	if cfg.Mem.CritWindow != 5*time.Minute {
+		t.Fatalf("expected mem alert window 5m, got %s", cfg.Mem.CritWindow)
+	}
+	if cfg.Disk.CritWindow != 5*time.Minute {
+		t.Fatalf("expected disk alert window 5m, got %s", cfg.Disk.CritWindow)
 	}
 	if len(cfg.MountExclude) == 0 {
 		t.Fatalf("expected default mount exclude list")
@@ -36,6 +40,12 @@ func TestLoadFromDefaults(t *testing.T) {
 	if len(cfg.FstypeExclude) == 0 {
 		t.Fatalf("expected default fstype exclude list")
 	}
+	if cfg.DiskFillLookback != time.Hour || cfg.DiskFillWarn != 0 || cfg.DiskFillCrit != 0 {
+		t.Fatalf("expected disk fill forecast disabled with 1h lookback, got %s/%s/%s", cfg.DiskFillLookback, cfg.DiskFillWarn, cfg.DiskFillCrit)
+	}
+	if cfg.StateDir != "" || cfg.StateMaxAge != time.Hour {
+		t.Fatalf("expected state persistence disabled by default, got %q/%s", cfg.StateDir, cfg.StateMaxAge)
+	}
 }
 
 func TestLoadFromEnvAndArgs(t *testing.T) {
@@ -51,6 +61,7 @@ func TestLoadFromEnvAndArgs(t *testing.T) {
 		"TELEGRAM_BOT_TOKEN": "token",
 		"TELEGRAM_CHAT_ID":   "chat",
 		"TELEGRAM_SCHEDULE":  "0 12 * * 1",
+		"STATE_DIR":          " /var/lib/ssm ",
 	}
 	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-interval", "30s"})
 
@@ -60,17 +71,17 @@ func TestLoadFromEnvAndArgs(t *testing.T) {
 	if cfg.TelegramSchedule != "0 12 * * 1" {
 		t.Fatalf("expected telegram schedule from env, got %s", cfg.TelegramSchedule)
 	}
-	if cfg.CPUThreshold != 100 {
-		t.Fatalf("expected cpu threshold clamped to 100, got %.1f", cfg.CPUThreshold)
+	if cfg.CPU.CritThreshold != 100 {
+		t.Fatalf("expected cpu threshold clamped to 100, got %.1f", cfg.CPU.CritThreshold)
 	}
-	if cfg.CPUAlertWindow != 2*time.Minute {
-		t.Fatalf("expected cpu alert window 2m, got %s", cfg.CPUAlertWindow)
+	if cfg.CPU.CritWindow != 2*time.Minute || cfg.CPU.WarnWindow != 2*time.Minute {
+		t.Fatalf("expected cpu alert windows 2m, got %s/%s", cfg.CPU.WarnWindow, cfg.CPU.CritWindow)
 	}
-	if cfg.MemThreshold != 50 {
-		t.Fatalf("expected mem threshold 50, got %.1f", cfg.MemThreshold)
+	if cfg.Mem.CritThreshold != 50 || cfg.Mem.WarnThreshold != 0 {
+		t.Fatalf("expected legacy mem threshold 50 without warn level, got %.1f/%.1f", cfg.Mem.CritThreshold, cfg.Mem.WarnThreshold)
 	}
-	if cfg.DiskThreshold != 70 {
-		t.Fatalf("expected disk threshold 70, got %.1f", cfg.DiskThreshold)
+	if cfg.Disk.CritThreshold != 70 {
+		t.Fatalf("expected disk threshold 70, got %.1f", cfg.Disk.CritThreshold)
 	}
 	if cfg.MountInclude != nil {
 		t.Fatalf("expected mount include nil for 'none'")
@@ -81,4 +92,541 @@ func TestLoadFromEnvAndArgs(t *testing.T) {
 	if cfg.TelegramToken != "token" || cfg.TelegramChatID != "chat" {
 		t.Fatalf("expected telegram credentials from env")
 	}
+	if cfg.StateDir != "/var/lib/ssm" {
+		t.Fatalf("expected state dir from env, got %q", cfg.StateDir)
+	}
+}
+
+func TestLoadFromWarnAndCritLevels(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"CPU_WARN_THRESHOLD": "60",
+		"CPU_CRIT_THRESHOLD": "85",
+		"CPU_THRESHOLD":      "95",
+		"CPU_WARN_WINDOW":    "10m",
+		"CPU_ALERT_WINDOW":   "2m",
+		"MEM_WARN_THRESHOLD": "0",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-disk-threshold", "80", "-disk-crit-window", "1m", "-disk-alert-window", "3m"})
+
+	if cfg.CPU.WarnThreshold != 60 || cfg.CPU.CritThreshold != 85 {
+		t.Fatalf("expected cpu levels 60/85, got %.1f/%.1f", cfg.CPU.WarnThreshold, cfg.CPU.CritThreshold)
+	}
+	if cfg.CPU.WarnWindow != 10*time.Minute || cfg.CPU.CritWindow != 2*time.Minute {
+		t.Fatalf("expected cpu windows 10m/2m, got %s/%s", cfg.CPU.WarnWindow, cfg.CPU.CritWindow)
+	}
+	if cfg.Mem.WarnThreshold != 0 {
+		t.Fatalf("expected mem warn disabled, got %.1f", cfg.Mem.WarnThreshold)
+	}
+	if cfg.Disk.CritThreshold != 80 {
+		t.Fatalf("expected legacy disk flag to set crit threshold, got %.1f", cfg.Disk.CritThreshold)
+	}
+	if cfg.Disk.CritWindow != time.Minute || cfg.Disk.WarnWindow != 3*time.Minute {
+		t.Fatalf("expected disk windows 3m/1m, got %s/%s", cfg.Disk.WarnWindow, cfg.Disk.CritWindow)
+	}
+}
+
+func TestLoadFromDiskMountThresholds(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"DISK_CRIT_WINDOW":      "2m",
+		"DISK_MOUNT_THRESHOLDS": "/boot=80:1m:crit, /media*=97:warn, /media*=99, bad, /x=nope, /y=50:soon",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{})
+
+	if len(cfg.DiskMounts) != 2 {
+		t.Fatalf("expected two mount rules, got %#v", cfg.DiskMounts)
+	}
+	boot := cfg.DiskMounts[0]
+	if boot.Pattern != "/boot" || boot.Rule.CritThreshold != 80 || boot.Rule.CritWindow != time.Minute || boot.Rule.WarnThreshold != 0 {
+		t.Fatalf("unexpected /boot rule: %#v", boot)
+	}
+	media := cfg.DiskMounts[1]
+	if media.Pattern != "/media*" || media.Rule.WarnThreshold != 97 || media.Rule.CritThreshold != 99 {
+		t.Fatalf("unexpected /media* rule: %#v", media)
+	}
+	if media.Rule.CritWindow != 2*time.Minute || media.Rule.WarnWindow != 5*time.Minute {
+		t.Fatalf("expected default windows on /media*, got %s/%s", media.Rule.WarnWindow, media.Rule.CritWindow)
+	}
+}
+
+func TestLoadFromInvalidMountRules(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"DISK_MOUNT_THRESHOLDS":       "/boot=80,/data=abc,/var=90:5x,/srv",
+		"DISK_MOUNT_MIN_FREE":         "/tmp=1XB",
+		"DISK_MOUNT_INODE_THRESHOLDS": "/home=80:clear=x",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	if len(cfg.DiskMounts) != 1 || cfg.DiskMounts[0].Pattern != "/boot" {
+		t.Fatalf("expected only the valid mount rule, got %#v", cfg.DiskMounts)
+	}
+	wants := []string{
+		`DISK_MOUNT_THRESHOLDS "/data=abc": invalid threshold`,
+		`DISK_MOUNT_THRESHOLDS "/var=90:5x": invalid window or severity "5x"`,
+		`DISK_MOUNT_THRESHOLDS "/srv": expected pattern=threshold`,
+		`DISK_MOUNT_MIN_FREE "/tmp=1XB": invalid threshold`,
+		`DISK_MOUNT_INODE_THRESHOLDS "/home=80:clear=x": invalid clear level`,
+	}
+	if len(cfg.MountRuleErrors) != len(wants) {
+		t.Fatalf("expected %d rejected mount rules, got %v", len(wants), cfg.MountRuleErrors)
+	}
+	for i, want := range wants {
+		if !strings.Contains(cfg.MountRuleErrors[i].Error(), want) {
+			t.Fatalf("rejected mount rule %d = %v, want %q", i, cfg.MountRuleErrors[i], want)
+		}
+	}
+}
+
+func TestLoadFromDiskMinFree(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"DISK_MIN_FREE":          "10GiB",
+		"DISK_FILL_WARN_HORIZON": "6h",
+		"DISK_MOUNT_MIN_FREE":    "/boot=100MiB:warn,/data*=1TiB:10m",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-disk-warn-min-free", "20GiB"})
+
+	if cfg.DiskFree.CritThreshold != 10<<30 || cfg.DiskFree.WarnThreshold != 20<<30 {
+		t.Fatalf("unexpected disk free thresholds: %#v", cfg.DiskFree)
+	}
+	if cfg.DiskFree.CritWindow != cfg.Disk.CritWindow {
+		t.Fatalf("expected disk free window to follow disk window")
+	}
+	if cfg.DiskFillWarn != 6*time.Hour {
+		t.Fatalf("expected disk fill warn horizon 6h, got %s", cfg.DiskFillWarn)
+	}
+	if len(cfg.DiskFreeMounts) != 2 {
+		t.Fatalf("expected two mount rules, got %#v", cfg.DiskFreeMounts)
+	}
+	if cfg.DiskFreeMounts[0].Rule.WarnThreshold != 100<<20 {
+		t.Fatalf("unexpected /boot rule: %#v", cfg.DiskFreeMounts[0])
+	}
+	if cfg.DiskFreeMounts[1].Rule.CritThreshold != 1<<40 || cfg.DiskFreeMounts[1].Rule.CritWindow != 10*time.Minute {
+		t.Fatalf("unexpected /data* rule: %#v", cfg.DiskFreeMounts[1])
+	}
+}
+
+func TestLoadFromDiskMinFreeFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg := LoadFrom(fs, func(string) string { return "" }, []string{"-disk-min-free", "5GiB"})
+	if cfg.DiskFree.CritThreshold != 5<<30 {
+		t.Fatalf("expected -disk-min-free to set the crit threshold, got %#v", cfg.DiskFree)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg = LoadFrom(fs, func(string) string { return "" }, []string{"-disk-min-free", "5GiB", "-disk-crit-min-free", "8GiB"})
+	if cfg.DiskFree.CritThreshold != 8<<30 {
+		t.Fatalf("expected -disk-crit-min-free to win over the alias, got %#v", cfg.DiskFree)
+	}
+}
+
+func TestLoadFromRenotify(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"ALERT_RENOTIFY":        "6h",
+		"DISK_RENOTIFY":         "24h",
+		"DISK_MOUNT_THRESHOLDS": "/boot=80",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-mem-renotify", "1h"})
+
+	if cfg.CPU.Renotify != 6*time.Hour {
+		t.Fatalf("expected cpu renotify from global, got %s", cfg.CPU.Renotify)
+	}
+	if cfg.Mem.Renotify != time.Hour {
+		t.Fatalf("expected mem renotify from flag, got %s", cfg.Mem.Renotify)
+	}
+	if cfg.Disk.Renotify != 24*time.Hour || cfg.DiskFree.Renotify != 24*time.Hour {
+		t.Fatalf("expected disk renotify from env, got %s/%s", cfg.Disk.Renotify, cfg.DiskFree.Renotify)
+	}
+	if cfg.DiskMounts[0].Rule.Renotify != 24*time.Hour {
+		t.Fatalf("expected mount rule to inherit disk renotify, got %s", cfg.DiskMounts[0].Rule.Renotify)
+	}
+}
+
+func TestLoadFromClearLevels(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"CPU_CRIT_CLEAR":           "80",
+		"CPU_CLEAR_WINDOW":         "2m",
+		"DISK_CLEAR_WINDOW":        "10m",
+		"DISK_MOUNT_THRESHOLDS":    "/boot=80:crit:clear=70",
+		"DISK_CRIT_MIN_FREE":       "10GiB",
+		"DISK_CRIT_MIN_FREE_CLEAR": "12GiB",
+		"DISK_MOUNT_MIN_FREE":      "/data=1TiB:clear=1.5TiB,/bad=1GiB:clear=x",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-mem-warn-clear", "60"})
+
+	if cfg.CPU.CritClear != 80 || cfg.CPU.ClearWindow != 2*time.Minute {
+		t.Fatalf("unexpected cpu clear settings: %#v", cfg.CPU)
+	}
+	if cfg.Mem.WarnClear != 60 || cfg.Mem.CritClear != 0 {
+		t.Fatalf("unexpected mem clear settings: %#v", cfg.Mem)
+	}
+	if cfg.DiskMounts[0].Rule.CritClear != 70 || cfg.DiskMounts[0].Rule.ClearWindow != 10*time.Minute {
+		t.Fatalf("unexpected /boot clear settings: %#v", cfg.DiskMounts[0].Rule)
+	}
+	if cfg.DiskFree.CritClear != 12<<30 || cfg.DiskFree.ClearWindow != 10*time.Minute {
+		t.Fatalf("unexpected disk free clear settings: %#v", cfg.DiskFree)
+	}
+	if len(cfg.DiskFreeMounts) != 1 || cfg.DiskFreeMounts[0].Rule.CritClear != 3<<39 {
+		t.Fatalf("unexpected disk free mount rules: %#v", cfg.DiskFreeMounts)
+	}
+}
+
+func TestLoadFromSilences(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"SILENCES": "0 3 * * 0|2h|disk:/mnt/* ; 2024-06-01T22:00:00Z/2024-06-02T02:00:00Z ; 2024-06-02T02:00:00Z/2024-06-01T22:00:00Z ; @daily|x ; 0 4 * * *|30m|CPU ; 0 */6 * * *|1h|cpu ; 2024-06-01/2024-06-02",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	if len(cfg.Silences) != 4 {
+		t.Fatalf("expected 4 valid silences, got %#v", cfg.Silences)
+	}
+	if len(cfg.SilenceErrors) != 3 {
+		t.Fatalf("expected 3 rejected silences, got %v", cfg.SilenceErrors)
+	}
+	for i, want := range []string{"end is not after start", "invalid duration", "invalid start"} {
+		if !strings.Contains(cfg.SilenceErrors[i].Error(), want) {
+			t.Fatalf("rejected silence %d = %v, want %q", i, cfg.SilenceErrors[i], want)
+		}
+	}
+	if step := cfg.Silences[3]; step.Schedule != "0 */6 * * *" || step.Duration != time.Hour || step.Kind != "cpu" {
+		t.Fatalf("expected cron step schedule to parse, got %#v", step)
+	}
+	recurring := cfg.Silences[0]
+	if recurring.Schedule != "0 3 * * 0" || recurring.Duration != 2*time.Hour || recurring.Kind != "disk" || recurring.Resource != "/mnt/*" {
+		t.Fatalf("unexpected recurring silence: %#v", recurring)
+	}
+	absolute := cfg.Silences[1]
+	if !absolute.Start.Equal(time.Date(2024, 6, 1, 22, 0, 0, 0, time.UTC)) || !absolute.End.Equal(time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC)) || absolute.Kind != "" {
+		t.Fatalf("unexpected absolute silence: %#v", absolute)
+	}
+	if cfg.Silences[2].Kind != "cpu" || cfg.Silences[2].Resource != "" {
+		t.Fatalf("unexpected scoped silence: %#v", cfg.Silences[2])
+	}
+}
+
+func TestLoadFromSwapAndLoad(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"SWAP_CRIT_THRESHOLD": "150",
+		"LOAD_WARN_THRESHOLD": "1.5",
+		"LOAD_CRIT_WINDOW":    "15m",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-load-crit-threshold", "3"})
+
+	if cfg.Swap.CritThreshold != 100 || cfg.Swap.WarnThreshold != 0 {
+		t.Fatalf("unexpected swap rule: %#v", cfg.Swap)
+	}
+	if cfg.Load.WarnThreshold != 1.5 || cfg.Load.CritThreshold != 3 || cfg.Load.CritWindow != 15*time.Minute || cfg.Load.WarnWindow != 5*time.Minute {
+		t.Fatalf("unexpected load rule: %#v", cfg.Load)
+	}
+	if fs.Lookup("load-threshold") != nil {
+		t.Fatalf("expected no legacy alias for load")
+	}
+}
+
+func TestLoadFromCPUTimeRules(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"CPU_STEAL_WARN_THRESHOLD": "10"}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-cpu-iowait-crit-threshold", "30", "-cpu-iowait-crit-window", "10m"})
+
+	if cfg.Steal.WarnThreshold != 10 || cfg.Steal.CritThreshold != 0 {
+		t.Fatalf("unexpected steal rule: %#v", cfg.Steal)
+	}
+	if cfg.IOWait.CritThreshold != 30 || cfg.IOWait.CritWindow != 10*time.Minute {
+		t.Fatalf("unexpected iowait rule: %#v", cfg.IOWait)
+	}
+	if cfg.CPU.CritThreshold != 90 {
+		t.Fatalf("expected cpu rule untouched, got %#v", cfg.CPU)
+	}
+}
+
+func TestLoadFromNetwork(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"NET_CRIT_THRESHOLD": "900", "IFACE_INCLUDE": "eth*,wg0"}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-net-errors-warn-threshold", "1"})
+
+	if cfg.Net.CritThreshold != 900 || cfg.Net.WarnThreshold != 0 {
+		t.Fatalf("unexpected net rule: %#v", cfg.Net)
+	}
+	if cfg.NetErrors.WarnThreshold != 1 {
+		t.Fatalf("unexpected net errors rule: %#v", cfg.NetErrors)
+	}
+	if len(cfg.IfaceInclude) != 2 || cfg.IfaceInclude[0] != "eth*" {
+		t.Fatalf("unexpected iface include: %#v", cfg.IfaceInclude)
+	}
+	if len(cfg.IfaceExclude) != 2 || cfg.IfaceExclude[0] != "lo" {
+		t.Fatalf("expected default iface exclude, got %#v", cfg.IfaceExclude)
+	}
+}
+
+func TestLoadFromDiskIO(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"DISK_UTIL_WARN_THRESHOLD": "90", "DISK_LATENCY_CRIT_THRESHOLD": "250"}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	if cfg.DiskUtil.WarnThreshold != 90 || cfg.DiskUtil.CritThreshold != 0 {
+		t.Fatalf("unexpected disk util rule: %#v", cfg.DiskUtil)
+	}
+	if cfg.DiskLatency.CritThreshold != 250 {
+		t.Fatalf("expected latency above 100 to be kept, got %#v", cfg.DiskLatency)
+	}
+}
+
+func TestLoadFromInodes(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cfg := LoadFrom(fs, func(string) string { return "" }, nil); cfg.Inodes.CritThreshold != 0 || cfg.Inodes.WarnThreshold != 0 {
+		t.Fatalf("expected inode alerts disabled by default, got %#v", cfg.Inodes)
+	}
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"INODE_CRIT_THRESHOLD": "90", "INODE_CRIT_WINDOW": "1m", "DISK_MOUNT_INODE_THRESHOLDS": "/var*=80:warn"}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	if cfg.Inodes.CritThreshold != 90 || cfg.Inodes.WarnThreshold != 0 || cfg.Inodes.CritWindow != time.Minute {
+		t.Fatalf("unexpected inode rule: %#v", cfg.Inodes)
+	}
+	if len(cfg.InodeMounts) != 1 || cfg.InodeMounts[0].Rule.WarnThreshold != 80 || cfg.InodeMounts[0].Rule.CritThreshold != 0 {
+		t.Fatalf("unexpected inode mount rules: %#v", cfg.InodeMounts)
+	}
+}
+
+func TestLoadFromTemperature(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"TEMP_THRESHOLD": "85", "TEMP_ALERT_WINDOW": "2m", "TEMP_EXCLUDE": "thermal_zone*", "SYSFS_ROOT": "/host/sys"}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	if cfg.Temp.CritThreshold != 85 || cfg.Temp.CritWindow != 2*time.Minute || cfg.Temp.WarnThreshold != 0 {
+		t.Fatalf("unexpected temp rule: %#v", cfg.Temp)
+	}
+	if len(cfg.TempExclude) != 1 || cfg.SysfsRoot != "/host/sys" {
+		t.Fatalf("unexpected sensor settings: %#v %q", cfg.TempExclude, cfg.SysfsRoot)
+	}
+}
+
+func TestLoadFromTopProcesses(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg := LoadFrom(fs, func(string) string { return "" }, nil)
+	if cfg.TopProcesses != 5 || cfg.TopReport {
+		t.Fatalf("unexpected defaults: %d %v", cfg.TopProcesses, cfg.TopReport)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"TOP_PROCESSES": "3", "TOP_PROCESSES_REPORT": "true"}
+	cfg = LoadFrom(fs, func(key string) string { return env[key] }, []string{"-top-processes", "-1"})
+	if cfg.TopProcesses != 0 || !cfg.TopReport {
+		t.Fatalf("unexpected top process settings: %d %v", cfg.TopProcesses, cfg.TopReport)
+	}
+}
+
+func TestLoadFromProcessChecks(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"PROCESS_CHECKS":       "db=name:postgres; nginx=cmdline:^nginx: (master|worker)|min=2|max=5 ;app=pidfile:/run/app.pid|restart;bad=name:x|min=3|max=1;odd=exe:foo;broken=cmdline:;neg=name:x|min=-1;nolabel",
+		"PROCESS_ALERT_WINDOW": "30s",
+		"ALERT_RENOTIFY":       "1h",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	want := []ProcessCheck{
+		{Name: "db", Match: "name", Pattern: "postgres", Min: 1},
+		{Name: "nginx", Match: "cmdline", Pattern: "^nginx: (master|worker)", Min: 2, Max: 5},
+		{Name: "app", Match: "pidfile", Pattern: "/run/app.pid", Min: 1, Restart: true},
+	}
+	if len(cfg.Processes) != len(want) {
+		t.Fatalf("expected %d process checks, got %#v", len(want), cfg.Processes)
+	}
+	for i := range want {
+		if cfg.Processes[i] != want[i] {
+			t.Fatalf("process check %d = %#v, want %#v", i, cfg.Processes[i], want[i])
+		}
+	}
+	wantErrors := []string{
+		`"bad=name:x|min=3|max=1": max 1 is below min 3`,
+		`"odd=exe:foo": unknown match "exe"`,
+		`"broken=cmdline:": expected name:, cmdline: or pidfile: match`,
+		`"neg=name:x|min=-1": invalid option "min=-1"`,
+		`"nolabel": expected label=match`,
+	}
+	if len(cfg.ProcessErrors) != len(wantErrors) {
+		t.Fatalf("expected %d rejected process checks, got %v", len(wantErrors), cfg.ProcessErrors)
+	}
+	for i, want := range wantErrors {
+		if !strings.Contains(cfg.ProcessErrors[i].Error(), want) {
+			t.Fatalf("rejected process check %d = %v, want %q", i, cfg.ProcessErrors[i], want)
+		}
+	}
+	if cfg.ProcessWindow != 30*time.Second || cfg.ProcessRenotify != time.Hour {
+		t.Fatalf("unexpected process timing: %s %s", cfg.ProcessWindow, cfg.ProcessRenotify)
+	}
+}
+
+func TestLoadFromPressure(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"PSI_IO_WARN_THRESHOLD": "20", "PSI_MEMORY_CRIT_THRESHOLD": "150", "PSI_AVG": "10", "PROCFS_ROOT": "/host/proc"}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	if cfg.PressureIO.WarnThreshold != 20 || cfg.PressureMemory.CritThreshold != 100 || cfg.PressureCPU.WarnThreshold != 0 {
+		t.Fatalf("unexpected pressure rules: %#v %#v %#v", cfg.PressureCPU, cfg.PressureMemory, cfg.PressureIO)
+	}
+	if cfg.PressureWindow != 10 || cfg.ProcfsRoot != "/host/proc" {
+		t.Fatalf("unexpected pressure settings: %d %q", cfg.PressureWindow, cfg.ProcfsRoot)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg = LoadFrom(fs, func(string) string { return "" }, []string{"-psi-avg", "30"})
+	if cfg.PressureWindow != 60 || cfg.ProcfsRoot != "/proc" {
+		t.Fatalf("expected defaults for invalid average, got %d %q", cfg.PressureWindow, cfg.ProcfsRoot)
+	}
+}
+
+func TestLoadFromOOMKillAlert(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cfg := LoadFrom(fs, func(string) string { return "" }, nil); cfg.OOMKillAlert {
+		t.Fatalf("expected oom kill alert disabled by default")
+	}
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"OOM_KILL_ALERT": "true"}
+	if cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil); !cfg.OOMKillAlert {
+		t.Fatalf("expected OOM_KILL_ALERT=true to enable the alert")
+	}
+}
+
+func TestLoadFromTelegramRoute(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg := LoadFrom(fs, func(string) string { return "" }, nil)
+	if cfg.TelegramRoute != (NotifyRoute{MinSeverity: "warning", Reports: true}) {
+		t.Fatalf("unexpected default route: %#v", cfg.TelegramRoute)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{"TELEGRAM_MIN_SEVERITY": "CRIT", "TELEGRAM_REPORTS": "false"}
+	cfg = LoadFrom(fs, func(key string) string { return env[key] }, nil)
+	if cfg.TelegramRoute != (NotifyRoute{MinSeverity: "critical", Reports: false}) {
+		t.Fatalf("unexpected route: %#v", cfg.TelegramRoute)
+	}
+}
+
+func TestLoadFromWebhook(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"WEBHOOK_URL":          "https://hooks.example.com/ssm",
+		"WEBHOOK_HEADERS":      "Authorization: Bearer abc; X-Team:ops;broken",
+		"WEBHOOK_SECRET":       "s3cret",
+		"WEBHOOK_RETRIES":      "-1",
+		"WEBHOOK_MIN_SEVERITY": "critical",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-webhook-timeout", "3s"})
+
+	webhook := cfg.Webhook
+	if webhook.URL != "https://hooks.example.com/ssm" || webhook.Secret != "s3cret" || webhook.Timeout != 3*time.Second || webhook.Retries != 0 {
+		t.Fatalf("unexpected webhook config: %#v", webhook)
+	}
+	if len(webhook.Headers) != 2 || webhook.Headers["Authorization"] != "Bearer abc" || webhook.Headers["X-Team"] != "ops" {
+		t.Fatalf("unexpected headers: %#v", webhook.Headers)
+	}
+	if webhook.Route != (NotifyRoute{MinSeverity: "critical", Reports: true}) {
+		t.Fatalf("unexpected route: %#v", webhook.Route)
+	}
+}
+
+func TestLoadFromEmail(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"SMTP_HOST":     " smtp.example.com ",
+		"SMTP_USERNAME": "monitor",
+		"SMTP_PASSWORD": "secret",
+		"SMTP_FROM":     "Monitor <monitor@example.com>",
+		"SMTP_TO":       "ops@example.com, admin@example.com",
+		"SMTP_SECURITY": "SSL",
+		"SMTP_AUTH":     "LOGIN",
+		"SMTP_REPORTS":  "false",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-smtp-port", "465"})
+
+	email := cfg.Email
+	if email.Host != "smtp.example.com" || email.Port != 465 || email.Username != "monitor" || email.Password != "secret" || email.Timeout != 10*time.Second {
+		t.Fatalf("unexpected email config: %#v", email)
+	}
+	if len(email.To) != 2 || email.To[1] != "admin@example.com" || email.Security != "tls" || email.Auth != "login" {
+		t.Fatalf("unexpected email delivery settings: %#v", email)
+	}
+	if email.Route != (NotifyRoute{MinSeverity: "warning", Reports: false}) {
+		t.Fatalf("unexpected route: %#v", email.Route)
+	}
+
+	defaults := LoadFrom(flag.NewFlagSet("test", flag.ContinueOnError), func(string) string { return "" }, nil).Email
+	if defaults.Port != 587 || defaults.Security != "starttls" || defaults.Auth != "plain" {
+		t.Fatalf("unexpected email defaults: %#v", defaults)
+	}
+}
+
+func TestLoadFromSlack(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"SLACK_WEBHOOK_URL":  "https://hooks.slack.com/services/T/B/X",
+		"SLACK_CHANNEL":      "#ops",
+		"SLACK_USERNAME":     "monitor",
+		"SLACK_MIN_SEVERITY": "crit",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, []string{"-slack-timeout", "5s"})
+
+	slack := cfg.Slack
+	if slack.URL != "https://hooks.slack.com/services/T/B/X" || slack.Channel != "#ops" || slack.Username != "monitor" || slack.Timeout != 5*time.Second {
+		t.Fatalf("unexpected slack config: %#v", slack)
+	}
+	if slack.Route != (NotifyRoute{MinSeverity: "critical", Reports: true}) {
+		t.Fatalf("unexpected route: %#v", slack.Route)
+	}
+}
+
+func TestLoadFromDiscord(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	env := map[string]string{
+		"DISCORD_WEBHOOK_URL": "https://discord.com/api/webhooks/1/abc",
+		"DISCORD_USERNAME":    "monitor",
+		"DISCORD_RETRIES":     "-3",
+		"DISCORD_REPORTS":     "false",
+	}
+	cfg := LoadFrom(fs, func(key string) string { return env[key] }, nil)
+
+	discord := cfg.Discord
+	if discord.URL != "https://discord.com/api/webhooks/1/abc" || discord.Username != "monitor" || discord.Timeout != 10*time.Second || discord.Retries != 0 {
+		t.Fatalf("unexpected discord config: %#v", discord)
+	}
+	if discord.Route != (NotifyRoute{MinSeverity: "warning", Reports: false}) {
+		t.Fatalf("unexpected route: %#v", discord.Route)
+	}
 }
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"flag"
+	"strings"
+	"time"
+)
+
+type NotifyRoute struct {
+	MinSeverity string
+	Reports     bool
+}
+
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	Secret  string
+	Timeout time.Duration
+	Retries int
+	Route   NotifyRoute
+}
+
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	Security string
+	Auth     string
+	Timeout  time.Duration
+	Route    NotifyRoute
+}
+
+type SlackConfig struct {
+	URL      string
+	Channel  string
+	Username string
+	Timeout  time.Duration
+	Route    NotifyRoute
+}
+
+type DiscordConfig struct {
+	URL      string
+	Username string
+	Timeout  time.Duration
+	Retries  int
+	Route    NotifyRoute
+}
+
+type routeFlags struct {
+	minSeverity *string
+	reports     *bool
+}
+
+func registerRoute(fs *flag.FlagSet, getenv func(string) string, envPrefix string, flagPrefix string, label string) *routeFlags {
+	defaultMinSeverity := envString(getenv, envPrefix+"_MIN_SEVERITY", "warning")
+	defaultReports := envBool(getenv, envPrefix+"_REPORTS", true)
+	return &routeFlags{
+		minSeverity: fs.String(flagPrefix+"-min-severity", defaultMinSeverity, "lowest alert severity sent to "+label+": warning or critical"),
+		reports:     fs.Bool(flagPrefix+"-reports", defaultReports, "send scheduled reports to "+label),
+	}
+}
+
+func (r *routeFlags) route() NotifyRoute {
+	return NotifyRoute{MinSeverity: parseSeverity(*r.minSeverity), Reports: *r.reports}
+}
+
+func parseSeverity(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "crit", "critical":
+		return "critical"
+	default:
+		return "warning"
+	}
+}
+
+func parseEmailSecurity(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "tls", "ssl", "smtps":
+		return "tls"
+	case "none", "plain":
+		return "none"
+	default:
+		return "starttls"
+	}
+}
+
+func parseEmailAuth(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), "login") {
+		return "login"
+	}
+	return "plain"
+}
+
+func parseHeaders(value string) map[string]string {
+	headers := map[string]string{}
+	for _, item := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(item, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		headers[key] = strings.TrimSpace(val)
+	}
+	return headers
+}
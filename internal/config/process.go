@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ProcessCheck struct {
+	Name    string
+	Match   string
+	Pattern string
+	Min     int
+	Max     int
+	Restart bool
+}
+
+func parseProcessChecks(value string) ([]ProcessCheck, []error) {
+	checks := []ProcessCheck{}
+	errs := []error{}
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		check, err := parseProcessCheck(item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("process check %q: %w", item, err))
+			continue
+		}
+		checks = append(checks, check)
+	}
+	return checks, errs
+}
+
+func parseProcessCheck(item string) (ProcessCheck, error) {
+	name, spec, ok := strings.Cut(item, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return ProcessCheck{}, fmt.Errorf("expected label=match")
+	}
+	check := ProcessCheck{Name: name, Min: 1}
+	parts := strings.Split(spec, "|")
+	end := len(parts)
+	for ; end > 1; end-- {
+		known, ok := parseProcessOption(&check, parts[end-1])
+		if !known {
+			break
+		}
+		if !ok {
+			return ProcessCheck{}, fmt.Errorf("invalid option %q", strings.TrimSpace(parts[end-1]))
+		}
+	}
+	match, pattern, ok := strings.Cut(strings.Join(parts[:end], "|"), ":")
+	check.Match = strings.ToLower(strings.TrimSpace(match))
+	check.Pattern = strings.TrimSpace(pattern)
+	if !ok || check.Pattern == "" {
+		return ProcessCheck{}, fmt.Errorf("expected name:, cmdline: or pidfile: match")
+	}
+	switch check.Match {
+	case "name", "cmdline", "pidfile":
+	default:
+		return ProcessCheck{}, fmt.Errorf("unknown match %q", check.Match)
+	}
+	if check.Max > 0 && check.Max < check.Min {
+		return ProcessCheck{}, fmt.Errorf("max %d is below min %d", check.Max, check.Min)
+	}
+	return check, nil
+}
+
+func parseProcessOption(check *ProcessCheck, option string) (bool, bool) {
+	option = strings.ToLower(strings.TrimSpace(option))
+	if option == "restart" {
+		check.Restart = true
+		return true, true
+	}
+	key, raw, ok := strings.Cut(option, "=")
+	if !ok || (key != "min" && key != "max") {
+		return false, false
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || value < 0 {
+		return true, false
+	}
+	if key == "min" {
+		check.Min = value
+	} else {
+		check.Max = value
+	}
+	return true, true
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Silence struct {
+	Schedule string
+	Duration time.Duration
+	Start    time.Time
+	End      time.Time
+	Kind     string
+	Resource string
+}
+
+func parseSilences(value string) ([]Silence, []error) {
+	silences := []Silence{}
+	errs := []error{}
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		silence, err := parseSilence(item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("silence %q: %w", item, err))
+			continue
+		}
+		silences = append(silences, silence)
+	}
+	return silences, errs
+}
+
+func parseSilence(item string) (Silence, error) {
+	parts := strings.Split(item, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	var silence Silence
+	scope := ""
+	if isSilenceRange(parts[0]) {
+		if len(parts) > 2 {
+			return Silence{}, fmt.Errorf("expected start/end[|scope]")
+		}
+		start, end, _ := strings.Cut(parts[0], "/")
+		startAt, err := time.Parse(time.RFC3339, strings.TrimSpace(start))
+		if err != nil {
+			return Silence{}, fmt.Errorf("invalid start: %w", err)
+		}
+		endAt, err := time.Parse(time.RFC3339, strings.TrimSpace(end))
+		if err != nil {
+			return Silence{}, fmt.Errorf("invalid end: %w", err)
+		}
+		if !endAt.After(startAt) {
+			return Silence{}, fmt.Errorf("end is not after start")
+		}
+		silence.Start = startAt
+		silence.End = endAt
+		if len(parts) == 2 {
+			scope = parts[1]
+		}
+	} else {
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return Silence{}, fmt.Errorf("expected cron|duration[|scope]")
+		}
+		duration, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return Silence{}, fmt.Errorf("invalid duration: %w", err)
+		}
+		if duration <= 0 {
+			return Silence{}, fmt.Errorf("duration must be positive")
+		}
+		silence.Schedule = parts[0]
+		silence.Duration = duration
+		if len(parts) == 3 {
+			scope = parts[2]
+		}
+	}
+	kind, resource, _ := strings.Cut(scope, ":")
+	silence.Kind = strings.ToLower(strings.TrimSpace(kind))
+	silence.Resource = strings.TrimSpace(resource)
+	return silence, nil
+}
+
+func isSilenceRange(spec string) bool {
+	return strings.Contains(spec, "/") && !strings.HasPrefix(spec, "@") && !strings.ContainsAny(spec, " \t")
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zergo0/simple-system-monitor/internal/units"
+)
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1e12,
+	"tib": 1 << 40,
+	"p":   1 << 50,
+	"pb":  1e15,
+	"pib": 1 << 50,
+}
+
+func ParseSize(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("empty size")
+	}
+	end := 0
+	for end < len(value) && (value[end] == '.' || (value[end] >= '0' && value[end] <= '9')) {
+		end++
+	}
+	number, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	multiplier, ok := sizeUnits[strings.ToLower(strings.TrimSpace(value[end:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit in %q", value)
+	}
+	bytes := number * multiplier
+	if bytes > math.MaxUint64 {
+		return 0, fmt.Errorf("size %q out of range", value)
+	}
+	return uint64(bytes), nil
+}
+
+type sizeValue uint64
+
+func (s *sizeValue) String() string {
+	if s == nil {
+		return "0"
+	}
+	return units.Bytes(float64(*s))
+}
+
+func (s *sizeValue) Set(value string) error {
+	parsed, err := ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*s = sizeValue(parsed)
+	return nil
+}
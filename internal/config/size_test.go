@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]uint64{
+		"0":       0,
+		"512":     512,
+		"10GiB":   10 << 30,
+		"10gib":   10 << 30,
+		"10G":     10 << 30,
+		"1.5 GiB": 3 << 29,
+		"200MB":   200e6,
+		"2TiB":    2 << 40,
+		"100 KiB": 100 << 10,
+	}
+	for input, want := range cases {
+		got, err := ParseSize(input)
+		if err != nil {
+			t.Fatalf("ParseSize(%q) failed: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseSize(%q) = %d, want %d", input, got, want)
+		}
+	}
+	for _, input := range []string{"", "GiB", "10 apples", "-5GiB"} {
+		if _, err := ParseSize(input); err == nil {
+			t.Fatalf("expected ParseSize(%q) to fail", input)
+		}
+	}
+}
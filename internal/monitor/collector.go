@@ -0,0 +1,233 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
+	"go.uber.org/zap"
+)
+
+type Collector struct {
+	logger        *zap.Logger
+	hostname      string
+	filter        FilterConfig
+	sources       Sources
+	cpuTimes      []cpu.TimesStat
+	coreTimes     []cpu.TimesStat
+	netCounters   map[string]net.IOCountersStat
+	netAt         time.Time
+	diskCounters  map[string]disk.IOCountersStat
+	diskAt        time.Time
+	processChecks []ProcessCheck
+	processMain   map[string]int32
+	noPressure    bool
+	oomKills      uint64
+	hasOOMKills   bool
+}
+
+func NewCollector(logger *zap.Logger, hostname string, filter FilterConfig, sources Sources, processChecks []ProcessCheck) *Collector {
+	if sources.SysfsRoot == "" {
+		sources.SysfsRoot = "/sys"
+	}
+	if sources.ProcfsRoot == "" {
+		sources.ProcfsRoot = "/proc"
+	}
+	return &Collector{logger: logger, hostname: hostname, filter: filter, sources: sources, processChecks: processChecks}
+}
+
+func (c *Collector) Collect(ctx context.Context) (Metrics, error) {
+	logger := c.logger
+	if c.cpuTimes == nil {
+		c.sampleCPUTimes(ctx)
+	}
+	if c.netCounters == nil {
+		c.sampleNet(ctx, time.Now())
+	}
+	if c.diskCounters == nil {
+		c.sampleDiskIO(ctx, time.Now())
+	}
+	cpuPercents, err := cpu.PercentWithContext(ctx, 200*time.Millisecond, false)
+	if err != nil {
+		return Metrics{}, err
+	}
+	memStats, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return Metrics{}, err
+	}
+	partitions, err := disk.PartitionsWithContext(ctx, true)
+	if err != nil {
+		return Metrics{}, err
+	}
+
+	partitions = filterPartitions(partitions, c.filter)
+
+	disks := make([]DiskUsage, 0, len(partitions))
+	for _, part := range partitions {
+		usage, err := disk.UsageWithContext(ctx, part.Mountpoint)
+		if err != nil {
+			logger.Debug("disk usage failed", zap.String("mountpoint", part.Mountpoint), zap.Error(err))
+			continue
+		}
+		disks = append(disks, DiskUsage{
+			Mountpoint:        part.Mountpoint,
+			Device:            deviceName(part.Device),
+			Fstype:            part.Fstype,
+			UsedPercent:       usage.UsedPercent,
+			TotalBytes:        usage.Total,
+			UsedBytes:         usage.Used,
+			FreeBytes:         usage.Free,
+			InodesTotal:       usage.InodesTotal,
+			InodesUsed:        usage.InodesUsed,
+			InodesUsedPercent: usage.InodesUsedPercent,
+		})
+	}
+
+	cpuPercent := 0.0
+	if len(cpuPercents) > 0 {
+		cpuPercent = cpuPercents[0]
+	}
+
+	metrics := Metrics{
+		Hostname:   c.hostname,
+		CPUPercent: cpuPercent,
+		MemPercent: memStats.UsedPercent,
+		Memory:     memoryUsage(memStats),
+		Disks:      disks,
+	}
+	collectSystem(ctx, logger, &metrics)
+	c.collectCPUTimes(ctx, &metrics)
+	c.collectNet(ctx, &metrics)
+	c.collectDiskIO(ctx, &metrics)
+	metrics.Temperatures = readTemperatures(c.sources.SysfsRoot, c.filter)
+	c.collectPressure(&metrics)
+	c.collectOOMKills(&metrics)
+	c.collectProcesses(ctx, &metrics)
+	return metrics, nil
+}
+
+func (c *Collector) sampleCPUTimes(ctx context.Context) {
+	total, err := cpu.TimesWithContext(ctx, false)
+	if err != nil {
+		c.logger.Debug("cpu times failed", zap.Error(err))
+		return
+	}
+	cores, err := cpu.TimesWithContext(ctx, true)
+	if err != nil {
+		c.logger.Debug("per-core cpu times failed", zap.Error(err))
+	}
+	c.cpuTimes = total
+	c.coreTimes = cores
+}
+
+func (c *Collector) collectCPUTimes(ctx context.Context, metrics *Metrics) {
+	prevTotal := c.cpuTimes
+	prevCores := c.coreTimes
+	c.sampleCPUTimes(ctx)
+	if len(prevTotal) > 0 && len(c.cpuTimes) > 0 {
+		metrics.CPUTimes = cpuBreakdown(prevTotal[0], c.cpuTimes[0])
+	}
+	if len(prevCores) == len(c.coreTimes) {
+		metrics.CPUCorePercents = make([]float64, 0, len(c.coreTimes))
+		for i := range c.coreTimes {
+			metrics.CPUCorePercents = append(metrics.CPUCorePercents, cpuBusy(prevCores[i], c.coreTimes[i]))
+		}
+	}
+}
+
+func cpuBreakdown(prev cpu.TimesStat, cur cpu.TimesStat) CPUTimes {
+	total := cur.Total() - prev.Total()
+	if total <= 0 {
+		return CPUTimes{}
+	}
+	share := func(cur, prev float64) float64 {
+		return clampShare((cur - prev) / total * 100)
+	}
+	return CPUTimes{
+		User:    share(cur.User, prev.User),
+		System:  share(cur.System, prev.System),
+		Nice:    share(cur.Nice, prev.Nice),
+		Idle:    share(cur.Idle, prev.Idle),
+		IOWait:  share(cur.Iowait, prev.Iowait),
+		IRQ:     share(cur.Irq, prev.Irq),
+		SoftIRQ: share(cur.Softirq, prev.Softirq),
+		Steal:   share(cur.Steal, prev.Steal),
+	}
+}
+
+func cpuBusy(prev cpu.TimesStat, cur cpu.TimesStat) float64 {
+	total := cur.Total() - prev.Total()
+	if total <= 0 {
+		return 0
+	}
+	idle := (cur.Idle + cur.Iowait) - (prev.Idle + prev.Iowait)
+	return clampShare(100 - idle/total*100)
+}
+
+func clampShare(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 100 {
+		return 100
+	}
+	return value
+}
+
+func collectSystem(ctx context.Context, logger *zap.Logger, metrics *Metrics) {
+	cores, err := cpu.CountsWithContext(ctx, true)
+	if err != nil {
+		logger.Debug("cpu count failed", zap.Error(err))
+	}
+	metrics.CPUCores = cores
+
+	if swap, err := mem.SwapMemoryWithContext(ctx); err != nil {
+		logger.Debug("swap usage failed", zap.Error(err))
+	} else {
+		metrics.SwapPercent = swap.UsedPercent
+		metrics.SwapTotalBytes = swap.Total
+		metrics.SwapUsedBytes = swap.Used
+	}
+
+	if avg, err := load.AvgWithContext(ctx); err != nil {
+		logger.Debug("load average failed", zap.Error(err))
+	} else {
+		metrics.Load1 = avg.Load1
+		metrics.Load5 = avg.Load5
+		metrics.Load15 = avg.Load15
+		if cores > 0 {
+			metrics.LoadPerCore1 = avg.Load1 / float64(cores)
+			metrics.LoadPerCore5 = avg.Load5 / float64(cores)
+			metrics.LoadPerCore15 = avg.Load15 / float64(cores)
+		}
+	}
+
+	if bootTime, err := host.BootTimeWithContext(ctx); err != nil {
+		logger.Debug("boot time failed", zap.Error(err))
+	} else {
+		metrics.BootTime = time.Unix(int64(bootTime), 0).UTC()
+	}
+	if uptime, err := host.UptimeWithContext(ctx); err != nil {
+		logger.Debug("uptime failed", zap.Error(err))
+	} else {
+		metrics.Uptime = uptime
+	}
+}
+
+func (c *Collector) collectPressure(metrics *Metrics) {
+	pressure, err := readPressure(c.sources.ProcfsRoot)
+	if err != nil {
+		if !c.noPressure {
+			c.logger.Info("pressure stall information unavailable", zap.String("procfs_root", c.sources.ProcfsRoot), zap.Error(err))
+			c.noPressure = true
+		}
+		return
+	}
+	c.noPressure = false
+	metrics.Pressure = pressure
+}
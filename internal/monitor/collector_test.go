@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+)
+
+func TestCPUBreakdown(t *testing.T) {
+	prev := cpu.TimesStat{User: 100, System: 50, Idle: 800, Iowait: 20, Steal: 10, Irq: 5, Softirq: 5}
+	cur := cpu.TimesStat{User: 130, System: 60, Idle: 840, Iowait: 30, Steal: 20, Irq: 5, Softirq: 5}
+	got := cpuBreakdown(prev, cur)
+	want := CPUTimes{User: 30, System: 10, Idle: 40, IOWait: 10, Steal: 10}
+	if !closeTo(got.User, want.User) || !closeTo(got.System, want.System) || !closeTo(got.Idle, want.Idle) || !closeTo(got.IOWait, want.IOWait) || !closeTo(got.Steal, want.Steal) {
+		t.Fatalf("unexpected breakdown: %#v", got)
+	}
+	if got := cpuBreakdown(cur, cur); got != (CPUTimes{}) {
+		t.Fatalf("expected empty breakdown without elapsed time, got %#v", got)
+	}
+}
+
+func TestCPUBusy(t *testing.T) {
+	prev := cpu.TimesStat{User: 10, Idle: 90}
+	cur := cpu.TimesStat{User: 100, Idle: 95, Iowait: 5}
+	if got := cpuBusy(prev, cur); !closeTo(got, 90) {
+		t.Fatalf("expected 90%% busy, got %.2f", got)
+	}
+	if got := cpuBusy(cur, prev); got != 0 {
+		t.Fatalf("expected 0 for counter reset, got %.2f", got)
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 0.001
+}
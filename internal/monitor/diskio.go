@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"go.uber.org/zap"
+)
+
+type DiskIO struct {
+	Device           string   `json:"device"`
+	Mountpoints      []string `json:"mountpoints"`
+	ReadBytesPerSec  float64  `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64  `json:"write_bytes_per_sec"`
+	ReadIOPS         float64  `json:"read_iops"`
+	WriteIOPS        float64  `json:"write_iops"`
+	AwaitMs          float64  `json:"await_ms"`
+	UtilPercent      float64  `json:"util_percent"`
+}
+
+func (d DiskIO) IOPS() float64 {
+	return d.ReadIOPS + d.WriteIOPS
+}
+
+func (c *Collector) sampleDiskIO(ctx context.Context, now time.Time) {
+	counters, err := disk.IOCountersWithContext(ctx)
+	if err != nil {
+		c.logger.Debug("disk io counters failed", zap.Error(err))
+		return
+	}
+	c.diskCounters = counters
+	c.diskAt = now
+}
+
+func (c *Collector) collectDiskIO(ctx context.Context, metrics *Metrics) {
+	prev := c.diskCounters
+	prevAt := c.diskAt
+	c.sampleDiskIO(ctx, time.Now())
+	if prev == nil || !c.diskAt.After(prevAt) {
+		return
+	}
+	metrics.DiskIO = diskIO(prev, c.diskCounters, metrics.Disks, c.diskAt.Sub(prevAt))
+}
+
+func diskIO(prev map[string]disk.IOCountersStat, cur map[string]disk.IOCountersStat, disks []DiskUsage, elapsed time.Duration) []DiskIO {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return nil
+	}
+	mounts := make(map[string][]string, len(cur))
+	for device := range cur {
+		mounts[device] = []string{}
+	}
+	for _, d := range disks {
+		if _, ok := mounts[d.Device]; ok {
+			mounts[d.Device] = append(mounts[d.Device], d.Mountpoint)
+		}
+	}
+	devices := make([]string, 0, len(cur))
+	for device, counters := range cur {
+		if virtualBlockDevice(device) || counters.ReadCount+counters.WriteCount == 0 {
+			continue
+		}
+		devices = append(devices, device)
+	}
+	slices.Sort(devices)
+
+	usage := make([]DiskIO, 0, len(devices))
+	for _, device := range devices {
+		before, ok := prev[device]
+		if !ok {
+			continue
+		}
+		after, ok := cur[device]
+		if !ok {
+			continue
+		}
+		reads := counterDelta(before.ReadCount, after.ReadCount)
+		writes := counterDelta(before.WriteCount, after.WriteCount)
+		io := DiskIO{
+			Device:           device,
+			Mountpoints:      mounts[device],
+			ReadBytesPerSec:  float64(counterDelta(before.ReadBytes, after.ReadBytes)) / seconds,
+			WriteBytesPerSec: float64(counterDelta(before.WriteBytes, after.WriteBytes)) / seconds,
+			ReadIOPS:         float64(reads) / seconds,
+			WriteIOPS:        float64(writes) / seconds,
+			UtilPercent:      clampShare(float64(counterDelta(before.IoTime, after.IoTime)) / float64(elapsed.Milliseconds()) * 100),
+		}
+		if ops := reads + writes; ops > 0 {
+			waited := counterDelta(before.ReadTime, after.ReadTime) + counterDelta(before.WriteTime, after.WriteTime)
+			io.AwaitMs = float64(waited) / float64(ops)
+		}
+		usage = append(usage, io)
+	}
+	return usage
+}
+
+func virtualBlockDevice(device string) bool {
+	return strings.HasPrefix(device, "loop") || strings.HasPrefix(device, "ram")
+}
+
+func deviceName(device string) string {
+	if !strings.HasPrefix(device, "/dev/") {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	return filepath.Base(device)
+}
@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+func TestDiskIODeltas(t *testing.T) {
+	prev := map[string]disk.IOCountersStat{
+		"sda1":  {Name: "sda1", ReadCount: 100, WriteCount: 100, ReadBytes: 0, WriteBytes: 0, ReadTime: 1000, WriteTime: 1000, IoTime: 5000},
+		"sdb":   {Name: "sdb"},
+		"sdd":   {Name: "sdd", ReadCount: 10},
+		"dm-0":  {Name: "dm-0", WriteCount: 5},
+		"loop0": {Name: "loop0", ReadCount: 50},
+	}
+	cur := map[string]disk.IOCountersStat{
+		"sda1":  {Name: "sda1", ReadCount: 200, WriteCount: 400, ReadBytes: 10 << 20, WriteBytes: 20 << 20, ReadTime: 1500, WriteTime: 3500, IoTime: 10000},
+		"sdb":   {Name: "sdb"},
+		"sdd":   {Name: "sdd", ReadCount: 20, ReadBytes: 1 << 20},
+		"dm-0":  {Name: "dm-0", WriteCount: 5},
+		"loop0": {Name: "loop0", ReadCount: 60},
+	}
+	disks := []DiskUsage{
+		{Mountpoint: "/", Device: "sda1"},
+		{Mountpoint: "/srv", Device: "sda1"},
+		{Mountpoint: "/data", Device: "sdc"},
+		{Mountpoint: "/nfs"},
+	}
+	usage := diskIO(prev, cur, disks, 10*time.Second)
+	if len(usage) != 3 || usage[0].Device != "dm-0" || usage[2].Device != "sdd" {
+		t.Fatalf("expected every used non-virtual device with counters, got %#v", usage)
+	}
+	if usage[2].Mountpoints == nil || len(usage[2].Mountpoints) != 0 || usage[2].ReadIOPS != 1 {
+		t.Fatalf("expected unmounted device without mountpoints, got %#v", usage[2])
+	}
+	io := usage[1]
+	if io.Device != "sda1" || strings.Join(io.Mountpoints, ",") != "/,/srv" {
+		t.Fatalf("unexpected device mapping: %#v", io)
+	}
+	if io.ReadBytesPerSec != 1<<20 || io.WriteBytesPerSec != 2<<20 {
+		t.Fatalf("unexpected throughput: %#v", io)
+	}
+	if io.ReadIOPS != 10 || io.WriteIOPS != 30 || io.IOPS() != 40 {
+		t.Fatalf("unexpected iops: %#v", io)
+	}
+	if io.AwaitMs != 7.5 || io.UtilPercent != 50 {
+		t.Fatalf("unexpected await/util: %#v", io)
+	}
+}
+
+func TestDeviceName(t *testing.T) {
+	if got := deviceName("/dev/nonexistent-sdz9"); got != "nonexistent-sdz9" {
+		t.Fatalf("unexpected device name: %q", got)
+	}
+	if got := deviceName("server:/export"); got != "" {
+		t.Fatalf("expected no device for network mounts, got %q", got)
+	}
+}
+
+func TestFormatMetricsTextDiskIO(t *testing.T) {
+	metrics := Metrics{DiskIO: []DiskIO{{Device: "nvme0n1p2", Mountpoints: []string{"/"}, ReadBytesPerSec: 1.5 * (1 << 20), WriteIOPS: 120, AwaitMs: 2.25, UtilPercent: 35}}}
+	text := FormatMetricsText(metrics, testStatusLevels())
+	for _, want := range []string{"Disk I/O", "nvme0n1p2", "1.5MiB", "120", "2.2ms", "35.0%"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report, got:\n%s", want, text)
+		}
+	}
+	metrics.DiskIO = append(metrics.DiskIO, DiskIO{Device: "sdb", Mountpoints: []string{}})
+	if text := FormatMetricsText(metrics, testStatusLevels()); !strings.Contains(text, "sdb        -") {
+		t.Fatalf("expected placeholder for unmounted devices, got:\n%s", text)
+	}
+	if html := FormatMetricsHTML(metrics, testStatusLevels()); !strings.Contains(html, "nvme0n1p2") {
+		t.Fatalf("expected disk io table in html report, got:\n%s", html)
+	}
+}
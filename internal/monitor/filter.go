@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/net"
 )
 
 func filterPartitions(parts []disk.PartitionStat, filter FilterConfig) []disk.PartitionStat {
@@ -27,25 +28,47 @@ func filterPartitions(parts []disk.PartitionStat, filter FilterConfig) []disk.Pa
 	return filtered
 }
 
-func matchMount(list []string, mountpoint string) bool {
-	for _, item := range list {
-		if item == "" {
-			continue
-		}
-		if strings.HasSuffix(item, "*") {
-			prefix := strings.TrimSuffix(item, "*")
-			if strings.HasPrefix(mountpoint, prefix) {
-				return true
+func filterInterfaces(counters []net.IOCountersStat, filter FilterConfig) []net.IOCountersStat {
+	filtered := make([]net.IOCountersStat, 0, len(counters))
+	for _, counter := range counters {
+		if len(filter.IfaceInclude) > 0 {
+			if !matchMount(filter.IfaceInclude, counter.Name) {
+				continue
 			}
+		} else if matchMount(filter.IfaceExclude, counter.Name) {
 			continue
 		}
-		if mountpoint == item {
+		filtered = append(filtered, counter)
+	}
+	return filtered
+}
+
+func matchMount(list []string, mountpoint string) bool {
+	for _, item := range list {
+		if _, ok := MatchMountPattern(item, mountpoint); ok {
 			return true
 		}
 	}
 	return false
 }
 
+func MatchMountPattern(pattern string, mountpoint string) (int, bool) {
+	if pattern == "" {
+		return 0, false
+	}
+	if strings.HasSuffix(pattern, "*") {
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(mountpoint, prefix) {
+			return len(prefix), true
+		}
+		return 0, false
+	}
+	if mountpoint == pattern {
+		return len(pattern) + 1, true
+	}
+	return 0, false
+}
+
 func containsLower(list []string, value string) bool {
 	if value == "" {
 		return false
@@ -48,3 +48,25 @@ func TestFilterPartitionsFstypeExclude(t *testing.T) {
 		t.Fatalf("expected tmpfs excluded, got %#v", filtered)
 	}
 }
+
+func TestMatchMountPatternSpecificity(t *testing.T) {
+	exact, ok := MatchMountPattern("/data", "/data")
+	if !ok {
+		t.Fatalf("expected exact match")
+	}
+	prefix, ok := MatchMountPattern("/data*", "/data")
+	if !ok {
+		t.Fatalf("expected prefix match")
+	}
+	if exact <= prefix {
+		t.Fatalf("expected exact match to be more specific, got %d <= %d", exact, prefix)
+	}
+	short, _ := MatchMountPattern("/*", "/data/media")
+	long, _ := MatchMountPattern("/data/*", "/data/media")
+	if long <= short {
+		t.Fatalf("expected longer prefix to be more specific, got %d <= %d", long, short)
+	}
+	if _, ok := MatchMountPattern("/boot", "/boot/efi"); ok {
+		t.Fatalf("did not expect exact pattern to match subpath")
+	}
+}
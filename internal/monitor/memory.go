@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/mem"
+	"go.uber.org/zap"
+
+	"github.com/zergo0/simple-system-monitor/internal/units"
+)
+
+type MemoryUsage struct {
+	TotalBytes     uint64 `json:"total_bytes"`
+	UsedBytes      uint64 `json:"used_bytes"`
+	AvailableBytes uint64 `json:"available_bytes"`
+	CachedBytes    uint64 `json:"cached_bytes"`
+	BuffersBytes   uint64 `json:"buffers_bytes"`
+	DirtyBytes     uint64 `json:"dirty_bytes"`
+	SlabBytes      uint64 `json:"slab_bytes"`
+}
+
+func memoryUsage(stats *mem.VirtualMemoryStat) MemoryUsage {
+	return MemoryUsage{
+		TotalBytes:     stats.Total,
+		UsedBytes:      stats.Used,
+		AvailableBytes: stats.Available,
+		CachedBytes:    stats.Cached,
+		BuffersBytes:   stats.Buffers,
+		DirtyBytes:     stats.Dirty,
+		SlabBytes:      stats.Slab,
+	}
+}
+
+func (c *Collector) collectOOMKills(metrics *Metrics) {
+	count, err := readVMStat(c.sources.ProcfsRoot, "oom_kill")
+	if err != nil {
+		c.logger.Debug("oom kill counter failed", zap.Error(err))
+		return
+	}
+	if c.hasOOMKills {
+		metrics.OOMKills = counterDelta(c.oomKills, count)
+	}
+	c.oomKills = count
+	c.hasOOMKills = true
+}
+
+func readVMStat(root string, key string) (uint64, error) {
+	f, err := os.Open(filepath.Join(root, "vmstat"))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || name != key {
+			continue
+		}
+		return strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("%s not found in vmstat", key)
+}
+
+func formatMemoryLine(m MemoryUsage) string {
+	return fmt.Sprintf("Mem %.1f/%.1fGiB avail %s cached %s buffers %s dirty %s slab %s",
+		bytesToGiB(m.UsedBytes), bytesToGiB(m.TotalBytes),
+		units.Bytes(float64(m.AvailableBytes)),
+		units.Bytes(float64(m.CachedBytes)),
+		units.Bytes(float64(m.BuffersBytes)),
+		units.Bytes(float64(m.DirtyBytes)),
+		units.Bytes(float64(m.SlabBytes)),
+	)
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func writeVMStat(t *testing.T, root string, oomKills string) {
+	t.Helper()
+	content := "nr_free_pages 12345\npgfault 999\noom_kill " + oomKills + "\nnr_dirty 10\n"
+	if err := os.WriteFile(filepath.Join(root, "vmstat"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadVMStat(t *testing.T) {
+	root := t.TempDir()
+	writeVMStat(t, root, "7")
+	if got, err := readVMStat(root, "oom_kill"); err != nil || got != 7 {
+		t.Fatalf("readVMStat = %d, %v", got, err)
+	}
+	if _, err := readVMStat(root, "missing"); err == nil {
+		t.Fatalf("expected error for missing key")
+	}
+	if _, err := readVMStat(t.TempDir(), "oom_kill"); err == nil {
+		t.Fatalf("expected error without vmstat")
+	}
+}
+
+func TestCollectOOMKills(t *testing.T) {
+	root := t.TempDir()
+	c := NewCollector(zap.NewNop(), "test", FilterConfig{}, Sources{ProcfsRoot: root}, nil)
+	writeVMStat(t, root, "3")
+	metrics := Metrics{}
+	c.collectOOMKills(&metrics)
+	if metrics.OOMKills != 0 {
+		t.Fatalf("expected no kills on first sample, got %d", metrics.OOMKills)
+	}
+	writeVMStat(t, root, "5")
+	c.collectOOMKills(&metrics)
+	if metrics.OOMKills != 2 {
+		t.Fatalf("expected 2 kills since previous sample, got %d", metrics.OOMKills)
+	}
+	metrics = Metrics{}
+	c.collectOOMKills(&metrics)
+	if metrics.OOMKills != 0 {
+		t.Fatalf("expected no new kills, got %d", metrics.OOMKills)
+	}
+}
+
+func TestFormatMetricsTextMemory(t *testing.T) {
+	metrics := Metrics{
+		Memory: MemoryUsage{
+			TotalBytes:     16 << 30,
+			UsedBytes:      4 << 30,
+			AvailableBytes: 11 << 30,
+			CachedBytes:    6 << 30,
+			BuffersBytes:   512 << 20,
+			DirtyBytes:     3 << 20,
+			SlabBytes:      700 << 20,
+		},
+		OOMKills: 2,
+	}
+	text := FormatMetricsText(metrics, testStatusLevels())
+	for _, want := range []string{"Mem 4.0/16.0GiB avail 11.0GiB cached 6.0GiB buffers 512.0MiB dirty 3.0MiB slab 700.0MiB", "OOM kills 2 since previous check"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report, got:\n%s", want, text)
+		}
+	}
+}
@@ -1,94 +1,119 @@
 package monitor
 
 import (
-	"context"
 	"fmt"
 	"html"
 	"strings"
 	"time"
 	"unicode/utf8"
 
-	"github.com/shirou/gopsutil/v4/cpu"
-	"github.com/shirou/gopsutil/v4/disk"
-	"github.com/shirou/gopsutil/v4/mem"
-	"go.uber.org/zap"
+	"github.com/zergo0/simple-system-monitor/internal/units"
 )
 
 type FilterConfig struct {
 	MountInclude  []string
 	MountExclude  []string
 	FstypeExclude []string
+	IfaceInclude  []string
+	IfaceExclude  []string
+	SensorInclude []string
+	SensorExclude []string
 }
 
-type DiskUsage struct {
-	Mountpoint  string  `json:"mountpoint"`
-	Fstype      string  `json:"fstype"`
-	UsedPercent float64 `json:"used_percent"`
-	TotalBytes  uint64  `json:"total_bytes"`
-	UsedBytes   uint64  `json:"used_bytes"`
+type Sources struct {
+	SysfsRoot  string
+	ProcfsRoot string
 }
 
-type Metrics struct {
-	Hostname   string      `json:"hostname"`
-	CPUPercent float64     `json:"cpu_percent"`
-	MemPercent float64     `json:"mem_percent"`
-	Disks      []DiskUsage `json:"disks"`
-}
-
-func Collect(ctx context.Context, logger *zap.Logger, hostname string, filter FilterConfig) (Metrics, error) {
-	cpuPercents, err := cpu.PercentWithContext(ctx, 200*time.Millisecond, false)
-	if err != nil {
-		return Metrics{}, err
-	}
-	memStats, err := mem.VirtualMemoryWithContext(ctx)
-	if err != nil {
-		return Metrics{}, err
-	}
-	partitions, err := disk.PartitionsWithContext(ctx, true)
-	if err != nil {
-		return Metrics{}, err
-	}
-
-	partitions = filterPartitions(partitions, filter)
-
-	disks := make([]DiskUsage, 0, len(partitions))
-	for _, part := range partitions {
-		usage, err := disk.UsageWithContext(ctx, part.Mountpoint)
-		if err != nil {
-			logger.Debug("disk usage failed", zap.String("mountpoint", part.Mountpoint), zap.Error(err))
-			continue
-		}
-		disks = append(disks, DiskUsage{
-			Mountpoint:  part.Mountpoint,
-			Fstype:      part.Fstype,
-			UsedPercent: usage.UsedPercent,
-			TotalBytes:  usage.Total,
-			UsedBytes:   usage.Used,
-		})
-	}
-
-	cpuPercent := 0.0
-	if len(cpuPercents) > 0 {
-		cpuPercent = cpuPercents[0]
-	}
-
-	return Metrics{
-		Hostname:   hostname,
-		CPUPercent: cpuPercent,
-		MemPercent: memStats.UsedPercent,
-		Disks:      disks,
-	}, nil
+type DiskUsage struct {
+	Mountpoint        string  `json:"mountpoint"`
+	Device            string  `json:"device,omitempty"`
+	Fstype            string  `json:"fstype"`
+	UsedPercent       float64 `json:"used_percent"`
+	TotalBytes        uint64  `json:"total_bytes"`
+	UsedBytes         uint64  `json:"used_bytes"`
+	FreeBytes         uint64  `json:"free_bytes"`
+	InodesTotal       uint64  `json:"inodes_total"`
+	InodesUsed        uint64  `json:"inodes_used"`
+	InodesUsedPercent float64 `json:"inodes_used_percent"`
+}
+
+type CPUTimes struct {
+	User    float64 `json:"user"`
+	System  float64 `json:"system"`
+	Nice    float64 `json:"nice"`
+	Idle    float64 `json:"idle"`
+	IOWait  float64 `json:"iowait"`
+	IRQ     float64 `json:"irq"`
+	SoftIRQ float64 `json:"softirq"`
+	Steal   float64 `json:"steal"`
+}
+
+type Levels struct {
+	Warn float64
+	Crit float64
+}
+
+type MountLevels struct {
+	Pattern string
+	Levels  Levels
+}
+
+type StatusLevels struct {
+	CPU         Levels
+	Mem         Levels
+	Swap        Levels
+	Load        Levels
+	Steal       Levels
+	IOWait      Levels
+	Disk        Levels
+	DiskMounts  []MountLevels
+	Inodes      Levels
+	Temp        Levels
+	InodeMounts []MountLevels
 }
 
-func FormatMetricsHTML(metrics Metrics) string {
+type Metrics struct {
+	Hostname        string          `json:"hostname"`
+	CPUPercent      float64         `json:"cpu_percent"`
+	CPUCores        int             `json:"cpu_cores"`
+	CPUTimes        CPUTimes        `json:"cpu_times"`
+	CPUCorePercents []float64       `json:"cpu_core_percents"`
+	MemPercent      float64         `json:"mem_percent"`
+	Memory          MemoryUsage     `json:"memory"`
+	OOMKills        uint64          `json:"oom_kills"`
+	SwapPercent     float64         `json:"swap_percent"`
+	SwapTotalBytes  uint64          `json:"swap_total_bytes"`
+	SwapUsedBytes   uint64          `json:"swap_used_bytes"`
+	Load1           float64         `json:"load1"`
+	Load5           float64         `json:"load5"`
+	Load15          float64         `json:"load15"`
+	LoadPerCore1    float64         `json:"load_per_core1"`
+	LoadPerCore5    float64         `json:"load_per_core5"`
+	LoadPerCore15   float64         `json:"load_per_core15"`
+	Uptime          uint64          `json:"uptime_seconds"`
+	BootTime        time.Time       `json:"boot_time"`
+	Disks           []DiskUsage     `json:"disks"`
+	Interfaces      []NetUsage      `json:"interfaces"`
+	DiskIO          []DiskIO        `json:"disk_io"`
+	Temperatures    []Temperature   `json:"temperatures"`
+	Processes       []ProcessStatus `json:"processes"`
+	Pressure        Pressure        `json:"pressure"`
+}
+
+func FormatMetricsHTML(metrics Metrics, levels StatusLevels) string {
 	var b strings.Builder
 	host := html.EscapeString(metrics.Hostname)
 	_, _ = fmt.Fprintf(&b, "<b>Simple System Monitor</b>\n<i>%s</i>", host)
 
 	metricHeader := []string{"Metric", "Usage", "St"}
 	metricRows := [][]string{
-		{"CPU", fmt.Sprintf("%.1f%%", metrics.CPUPercent), statusEmoji(metrics.CPUPercent)},
-		{"MEM", fmt.Sprintf("%.1f%%", metrics.MemPercent), statusEmoji(metrics.MemPercent)},
+		{"CPU", fmt.Sprintf("%.1f%%", metrics.CPUPercent), statusEmoji(metrics.CPUPercent, levels.CPU)},
+		{"MEM", fmt.Sprintf("%.1f%%", metrics.MemPercent), statusEmoji(metrics.MemPercent, levels.Mem)},
+		{"SWAP", fmt.Sprintf("%.1f%%", metrics.SwapPercent), statusEmoji(metrics.SwapPercent, levels.Swap)},
+		{"LOAD/CORE", fmt.Sprintf("%.2f", metrics.LoadPerCore1), statusEmoji(metrics.LoadPerCore1, levels.Load)},
+		{"STEAL", fmt.Sprintf("%.1f%%", metrics.CPUTimes.Steal), statusEmoji(metrics.CPUTimes.Steal, levels.Steal)},
+		{"IOWAIT", fmt.Sprintf("%.1f%%", metrics.CPUTimes.IOWait), statusEmoji(metrics.CPUTimes.IOWait, levels.IOWait)},
 	}
 	metricNameWidth := displayWidth(metricHeader[0])
 	metricUseWidth := displayWidth(metricHeader[1])
@@ -101,28 +126,12 @@ func FormatMetricsHTML(metrics Metrics) string {
 	metricStatusWidth = maxInt(metricStatusWidth, 2)
 
 	maxMount := maxMountWidth(metrics.Disks, 24)
-	diskHeader := []string{"Mount", "Usage", "St", "Used/Total"}
 	diskRows := make([][]string, 0, len(metrics.Disks))
-	mountWidth := displayWidth(diskHeader[0])
-	useWidth := displayWidth(diskHeader[1])
-	statusWidth := displayWidth(diskHeader[2])
-	sizeWidth := displayWidth(diskHeader[3])
-
 	for _, d := range metrics.Disks {
-		totalGiB := bytesToGiB(d.TotalBytes)
-		usedGiB := bytesToGiB(d.UsedBytes)
 		mount := formatMount(d.Mountpoint, maxMount)
-		use := fmt.Sprintf("%.1f%%", d.UsedPercent)
-		status := statusEmoji(d.UsedPercent)
-		size := fmt.Sprintf("%.1f/%.1fGiB", usedGiB, totalGiB)
-
-		diskRows = append(diskRows, []string{mount, use, status, size})
-		mountWidth = maxInt(mountWidth, displayWidth(mount))
-		useWidth = maxInt(useWidth, displayWidth(use))
-		statusWidth = maxInt(statusWidth, displayWidth(status))
-		sizeWidth = maxInt(sizeWidth, displayWidth(size))
+		status := statusEmojis[diskStatus(d, levels)]
+		diskRows = append(diskRows, []string{mount, fmt.Sprintf("%.1f%%", d.UsedPercent), status, formatDiskSize(d), formatInodes(d)})
 	}
-	statusWidth = maxInt(statusWidth, 2)
 
 	b.WriteString("\n<pre>\n")
 	b.WriteString(tableTop3(metricNameWidth, metricUseWidth, metricStatusWidth))
@@ -135,27 +144,85 @@ func FormatMetricsHTML(metrics Metrics) string {
 		}
 	}
 	b.WriteString(tableBottom3(metricNameWidth, metricUseWidth, metricStatusWidth))
+	b.WriteString("\n\n" + html.EscapeString(strings.Join(formatSystemLines(metrics), "\n")))
 	b.WriteString("\n\nDisk\n")
 	if len(metrics.Disks) == 0 {
-		b.WriteString("none\n</pre>")
-		return b.String()
-	}
-	b.WriteString(tableTop(mountWidth, useWidth, statusWidth, sizeWidth))
-	b.WriteString(tableRow(mountWidth, useWidth, statusWidth, sizeWidth, diskHeader))
-	b.WriteString(tableMid(mountWidth, useWidth, statusWidth, sizeWidth))
-	for i, row := range diskRows {
-		row[0] = html.EscapeString(row[0])
-		row[3] = html.EscapeString(row[3])
-		b.WriteString(tableRow(mountWidth, useWidth, statusWidth, sizeWidth, row))
-		if i < len(diskRows)-1 {
-			b.WriteString(tableMid(mountWidth, useWidth, statusWidth, sizeWidth))
-		}
-	}
-	b.WriteString(tableBottom(mountWidth, useWidth, statusWidth, sizeWidth))
+		b.WriteString("none")
+	} else {
+		writeTableHTML(&b, []string{"Mount", "Usage", "St", "Used/Total", "Inodes"}, diskRows, diskAlign)
+	}
+	writeDiskIOHTML(&b, metrics.DiskIO)
+	writeNetworkHTML(&b, metrics.Interfaces)
+	writeTemperatureHTML(&b, metrics.Temperatures, levels.Temp)
+	writeProcessCheckHTML(&b, metrics.Processes)
 	b.WriteString("</pre>")
 	return b.String()
 }
 
+func writeNetworkHTML(b *strings.Builder, interfaces []NetUsage) {
+	if len(interfaces) == 0 {
+		return
+	}
+	b.WriteString("\n\nNetwork\n")
+	writeTableHTML(b, networkHeader, networkRows(interfaces), networkAlign)
+}
+
+func writeDiskIOHTML(b *strings.Builder, devices []DiskIO) {
+	if len(devices) == 0 {
+		return
+	}
+	b.WriteString("\n\nDisk I/O\n")
+	writeTableHTML(b, diskIOHeader, diskIORows(devices), diskIOAlign)
+}
+
+func writeTemperatureHTML(b *strings.Builder, temps []Temperature, levels Levels) {
+	if len(temps) == 0 {
+		return
+	}
+	b.WriteString("\n\nTemperature\n")
+	writeTableHTML(b, []string{"Sensor", "Temp", "St"}, temperatureRows(temps, levels, statusEmojis), temperatureAlign)
+}
+
+func writeTableHTML(b *strings.Builder, header []string, rows [][]string, rightAlign []bool) {
+	widths := make([]int, len(header))
+	for i, col := range header {
+		widths[i] = displayWidth(col)
+	}
+	for _, row := range rows {
+		for i, col := range row {
+			widths[i] = maxInt(widths[i], displayWidth(col))
+		}
+	}
+	line := func(left, mid, right string) string {
+		parts := make([]string, len(widths))
+		for i, width := range widths {
+			parts[i] = strings.Repeat("─", width+2)
+		}
+		return left + strings.Join(parts, mid) + right
+	}
+	row := func(cols []string) string {
+		parts := make([]string, len(cols))
+		for i, col := range cols {
+			if rightAlign[i] {
+				parts[i] = html.EscapeString(padLeft(col, widths[i]))
+			} else {
+				parts[i] = html.EscapeString(padRight(col, widths[i]))
+			}
+		}
+		return "│ " + strings.Join(parts, " │ ") + " │\n"
+	}
+	b.WriteString(line("┌", "┬", "┐\n"))
+	b.WriteString(row(header))
+	b.WriteString(line("├", "┼", "┤\n"))
+	for i, cols := range rows {
+		b.WriteString(row(cols))
+		if i < len(rows)-1 {
+			b.WriteString(line("├", "┼", "┤\n"))
+		}
+	}
+	b.WriteString(line("└", "┴", "┘"))
+}
+
 func FormatMetricsHeaderText(metrics Metrics) string {
 	host := CleanText(metrics.Hostname)
 	if host == "" {
@@ -164,63 +231,260 @@ func FormatMetricsHeaderText(metrics Metrics) string {
 	return fmt.Sprintf("Simple System Monitor - %s", host)
 }
 
-func FormatMetricsText(metrics Metrics) string {
+func FormatMetricsText(metrics Metrics, levels StatusLevels) string {
 	lines := []string{}
 
 	metricHeader := []string{"Metric", "Usage", "Status"}
 	metricRows := [][]string{
-		{"CPU", fmt.Sprintf("%.1f%%", metrics.CPUPercent), statusLabel(metrics.CPUPercent)},
-		{"MEM", fmt.Sprintf("%.1f%%", metrics.MemPercent), statusLabel(metrics.MemPercent)},
+		{"CPU", fmt.Sprintf("%.1f%%", metrics.CPUPercent), statusLabel(metrics.CPUPercent, levels.CPU)},
+		{"MEM", fmt.Sprintf("%.1f%%", metrics.MemPercent), statusLabel(metrics.MemPercent, levels.Mem)},
+		{"SWAP", fmt.Sprintf("%.1f%%", metrics.SwapPercent), statusLabel(metrics.SwapPercent, levels.Swap)},
+		{"LOAD/CORE", fmt.Sprintf("%.2f", metrics.LoadPerCore1), statusLabel(metrics.LoadPerCore1, levels.Load)},
+		{"STEAL", fmt.Sprintf("%.1f%%", metrics.CPUTimes.Steal), statusLabel(metrics.CPUTimes.Steal, levels.Steal)},
+		{"IOWAIT", fmt.Sprintf("%.1f%%", metrics.CPUTimes.IOWait), statusLabel(metrics.CPUTimes.IOWait, levels.IOWait)},
 	}
 	lines = append(lines, formatTableLines(metricHeader, metricRows, []bool{false, true, false})...)
+	lines = append(lines, "")
+	lines = append(lines, formatSystemLines(metrics)...)
 	lines = append(lines, "", "Disk")
-	if len(metrics.Disks) == 0 {
-		lines = append(lines, "none")
-		return strings.Join(lines, "\n")
+	lines = append(lines, formatDiskLines(metrics.Disks, levels)...)
+	lines = append(lines, formatDiskIOLines(metrics.DiskIO)...)
+	lines = append(lines, formatNetworkLines(metrics.Interfaces)...)
+	lines = append(lines, formatTemperatureLines(metrics.Temperatures, levels.Temp)...)
+	lines = append(lines, formatProcessCheckLines(metrics.Processes)...)
+	return strings.Join(lines, "\n")
+}
+
+func formatDiskLines(disks []DiskUsage, levels StatusLevels) []string {
+	if len(disks) == 0 {
+		return []string{"none"}
+	}
+	maxMount := maxMountWidth(disks, 24)
+	diskHeader := []string{"Mount", "Usage", "Status", "Used/Total", "Inodes"}
+	diskRows := make([][]string, 0, len(disks))
+	for _, d := range disks {
+		mount := formatMountPlain(CleanText(d.Mountpoint), maxMount)
+		status := statusLabels[diskStatus(d, levels)]
+		diskRows = append(diskRows, []string{mount, fmt.Sprintf("%.1f%%", d.UsedPercent), status, formatDiskSize(d), formatInodes(d)})
 	}
+	return formatTableLines(diskHeader, diskRows, diskAlign)
+}
 
-	maxMount := maxMountWidth(metrics.Disks, 24)
-	diskHeader := []string{"Mount", "Usage", "Status", "Used/Total"}
-	diskRows := make([][]string, 0, len(metrics.Disks))
+type MetricField struct {
+	Name   string
+	Value  string
+	Status string
+}
+
+func MetricFields(metrics Metrics, levels StatusLevels) []MetricField {
+	fields := []MetricField{
+		{Name: "CPU", Value: fmt.Sprintf("%.1f%%", metrics.CPUPercent), Status: metricStatus(statusRank(metrics.CPUPercent, levels.CPU))},
+		{Name: "MEM", Value: fmt.Sprintf("%.1f%%", metrics.MemPercent), Status: metricStatus(statusRank(metrics.MemPercent, levels.Mem))},
+	}
 	for _, d := range metrics.Disks {
-		totalGiB := bytesToGiB(d.TotalBytes)
-		usedGiB := bytesToGiB(d.UsedBytes)
-		mount := formatMountPlain(CleanText(d.Mountpoint), maxMount)
-		use := fmt.Sprintf("%.1f%%", d.UsedPercent)
-		status := statusLabel(d.UsedPercent)
-		size := fmt.Sprintf("%.1f/%.1fGiB", usedGiB, totalGiB)
-		diskRows = append(diskRows, []string{mount, use, status, size})
+		fields = append(fields, MetricField{
+			Name:   "Disk " + CleanText(d.Mountpoint),
+			Value:  fmt.Sprintf("%.1f%% (%s)", d.UsedPercent, formatDiskSize(d)),
+			Status: metricStatus(diskStatus(d, levels)),
+		})
 	}
-	lines = append(lines, formatTableLines(diskHeader, diskRows, []bool{false, true, false, true})...)
-	return strings.Join(lines, "\n")
+	return fields
+}
+
+func metricStatus(rank int) string {
+	return statusEmojis[rank] + " " + statusLabels[rank]
+}
+
+var (
+	diskAlign        = []bool{false, true, false, true, true}
+	networkHeader    = []string{"Iface", "Rx", "Tx", "Err/Drop"}
+	networkAlign     = []bool{false, true, true, true}
+	diskIOHeader     = []string{"Device", "Mount", "Read/s", "Write/s", "IOPS", "Await", "Util"}
+	diskIOAlign      = []bool{false, false, true, true, true, true, true}
+	temperatureAlign = []bool{false, true, false}
+)
+
+func formatNetworkLines(interfaces []NetUsage) []string {
+	if len(interfaces) == 0 {
+		return nil
+	}
+	lines := []string{"", "Network"}
+	lines = append(lines, formatTableLines(networkHeader, networkRows(interfaces), networkAlign)...)
+	return lines
+}
+
+func formatDiskIOLines(devices []DiskIO) []string {
+	if len(devices) == 0 {
+		return nil
+	}
+	lines := []string{"", "Disk I/O"}
+	lines = append(lines, formatTableLines(diskIOHeader, diskIORows(devices), diskIOAlign)...)
+	return lines
+}
+
+func formatTemperatureLines(temps []Temperature, levels Levels) []string {
+	if len(temps) == 0 {
+		return nil
+	}
+	lines := []string{"", "Temperature"}
+	lines = append(lines, formatTableLines([]string{"Sensor", "Temp", "Status"}, temperatureRows(temps, levels, statusLabels), temperatureAlign)...)
+	return lines
+}
+
+func temperatureRows(temps []Temperature, levels Levels, status []string) [][]string {
+	rows := make([][]string, 0, len(temps))
+	for _, temp := range temps {
+		rows = append(rows, []string{
+			formatMountPlain(CleanText(temp.Sensor), 24),
+			fmt.Sprintf("%.1f°C", temp.Celsius),
+			status[statusRank(temp.Celsius, levels)],
+		})
+	}
+	return rows
+}
+
+func diskIORows(devices []DiskIO) [][]string {
+	rows := make([][]string, 0, len(devices))
+	for _, d := range devices {
+		mounts := "-"
+		if len(d.Mountpoints) > 0 {
+			mounts = formatMountPlain(CleanText(strings.Join(d.Mountpoints, ",")), 16)
+		}
+		rows = append(rows, []string{
+			CleanText(d.Device),
+			mounts,
+			units.Bytes(d.ReadBytesPerSec),
+			units.Bytes(d.WriteBytesPerSec),
+			fmt.Sprintf("%.0f", d.IOPS()),
+			fmt.Sprintf("%.1fms", d.AwaitMs),
+			fmt.Sprintf("%.1f%%", d.UtilPercent),
+		})
+	}
+	return rows
+}
+
+func networkRows(interfaces []NetUsage) [][]string {
+	rows := make([][]string, 0, len(interfaces))
+	for _, iface := range interfaces {
+		rows = append(rows, []string{
+			formatMountPlain(CleanText(iface.Name), 16),
+			units.BitRate(iface.RxBytesPerSec * 8),
+			units.BitRate(iface.TxBytesPerSec * 8),
+			fmt.Sprintf("%d/%d", iface.Errors(), iface.RxDrops+iface.TxDrops),
+		})
+	}
+	return rows
+}
+
+const coresPerLine = 8
+
+func formatSystemLines(metrics Metrics) []string {
+	times := metrics.CPUTimes
+	lines := []string{
+		fmt.Sprintf("CPU user %.1f%% sys %.1f%% iowait %.1f%% steal %.1f%% irq %.1f%%", times.User+times.Nice, times.System, times.IOWait, times.Steal, times.IRQ+times.SoftIRQ),
+		fmt.Sprintf("Load %.2f %.2f %.2f (%d cores)", metrics.Load1, metrics.Load5, metrics.Load15, metrics.CPUCores),
+		formatMemoryLine(metrics.Memory),
+		fmt.Sprintf("Swap %.1f/%.1fGiB", bytesToGiB(metrics.SwapUsedBytes), bytesToGiB(metrics.SwapTotalBytes)),
+	}
+	for i := 0; i < len(metrics.CPUCorePercents); i += coresPerLine {
+		end := min(i+coresPerLine, len(metrics.CPUCorePercents))
+		cores := make([]string, 0, end-i)
+		for _, percent := range metrics.CPUCorePercents[i:end] {
+			cores = append(cores, fmt.Sprintf("%3.0f%%", percent))
+		}
+		lines = append(lines, fmt.Sprintf("Cores %d-%d %s", i, end-1, strings.Join(cores, " ")))
+	}
+	if metrics.OOMKills > 0 {
+		lines = append(lines, fmt.Sprintf("OOM kills %d since previous check", metrics.OOMKills))
+	}
+	if metrics.Pressure.Available {
+		lines = append(lines, formatPressureLine(metrics.Pressure))
+	}
+	if !metrics.BootTime.IsZero() {
+		lines = append(lines, fmt.Sprintf("Uptime %s (since %s)", formatUptime(metrics.Uptime), metrics.BootTime.UTC().Format("2006-01-02 15:04 UTC")))
+	}
+	return lines
+}
+
+func formatUptime(seconds uint64) string {
+	days := seconds / 86400
+	hours := seconds % 86400 / 3600
+	minutes := seconds % 3600 / 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
 }
 
 func bytesToGiB(value uint64) float64 {
 	return float64(value) / (1024 * 1024 * 1024)
 }
 
-func statusEmoji(percent float64) string {
-	switch {
-	case percent >= 90:
-		return "🟥"
-	case percent >= 75:
-		return "🟨"
-	default:
-		return "🟩"
+func (l StatusLevels) DiskLevels(mountpoint string) Levels {
+	return mountLevels(l.Disk, l.DiskMounts, mountpoint)
+}
+
+func (l StatusLevels) InodeLevels(mountpoint string) Levels {
+	return mountLevels(l.Inodes, l.InodeMounts, mountpoint)
+}
+
+func mountLevels(levels Levels, mounts []MountLevels, mountpoint string) Levels {
+	best := -1
+	for _, mount := range mounts {
+		if score, ok := MatchMountPattern(mount.Pattern, mountpoint); ok && score > best {
+			best = score
+			levels = mount.Levels
+		}
 	}
+	return levels
+}
+
+var (
+	statusEmojis = []string{"🟩", "🟨", "🟥"}
+	statusLabels = []string{"OK", "WARN", "ALERT"}
+)
+
+func statusEmoji(percent float64, levels Levels) string {
+	return statusEmojis[statusRank(percent, levels)]
 }
 
-func statusLabel(percent float64) string {
+func statusLabel(percent float64, levels Levels) string {
+	return statusLabels[statusRank(percent, levels)]
+}
+
+func statusRank(percent float64, levels Levels) int {
 	switch {
-	case percent >= 90:
-		return "ALERT"
-	case percent >= 75:
-		return "WARN"
+	case levels.Crit > 0 && percent >= levels.Crit:
+		return 2
+	case levels.Warn > 0 && percent >= levels.Warn:
+		return 1
 	default:
-		return "OK"
+		return 0
 	}
 }
 
+func diskStatus(d DiskUsage, levels StatusLevels) int {
+	rank := statusRank(d.UsedPercent, levels.DiskLevels(d.Mountpoint))
+	if d.InodesTotal > 0 {
+		rank = max(rank, statusRank(d.InodesUsedPercent, levels.InodeLevels(d.Mountpoint)))
+	}
+	return rank
+}
+
+func formatDiskSize(d DiskUsage) string {
+	return fmt.Sprintf("%.1f/%.1fGiB", bytesToGiB(d.UsedBytes), bytesToGiB(d.TotalBytes))
+}
+
+func formatInodes(d DiskUsage) string {
+	if d.InodesTotal == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", d.InodesUsedPercent)
+}
+
 func maxMountWidth(disks []DiskUsage, max int) int {
 	width := 1
 	for _, d := range disks {
@@ -323,41 +587,6 @@ func tableRow3(nameW, useW, statusW int, cols []string) string {
 	return fmt.Sprintf("│ %s │ %s │ %s │\n", name, use, status)
 }
 
-func tableTop(mountW, useW, statusW, sizeW int) string {
-	return fmt.Sprintf("┌%s┬%s┬%s┬%s┐\n",
-		strings.Repeat("─", mountW+2),
-		strings.Repeat("─", useW+2),
-		strings.Repeat("─", statusW+2),
-		strings.Repeat("─", sizeW+2),
-	)
-}
-
-func tableMid(mountW, useW, statusW, sizeW int) string {
-	return fmt.Sprintf("├%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", mountW+2),
-		strings.Repeat("─", useW+2),
-		strings.Repeat("─", statusW+2),
-		strings.Repeat("─", sizeW+2),
-	)
-}
-
-func tableBottom(mountW, useW, statusW, sizeW int) string {
-	return fmt.Sprintf("└%s┴%s┴%s┴%s┘",
-		strings.Repeat("─", mountW+2),
-		strings.Repeat("─", useW+2),
-		strings.Repeat("─", statusW+2),
-		strings.Repeat("─", sizeW+2),
-	)
-}
-
-func tableRow(mountW, useW, statusW, sizeW int, cols []string) string {
-	mount := padRight(cols[0], mountW)
-	use := padLeft(cols[1], useW)
-	status := padRight(cols[2], statusW)
-	size := padRight(cols[3], sizeW)
-	return fmt.Sprintf("│ %s │ %s │ %s │ %s │\n", mount, use, status, size)
-}
-
 func formatTableLines(header []string, rows [][]string, rightAlign []bool) []string {
 	widths := make([]int, len(header))
 	for i, h := range header {
@@ -3,8 +3,14 @@ package monitor
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
+func testStatusLevels() StatusLevels {
+	levels := Levels{Warn: 75, Crit: 90}
+	return StatusLevels{CPU: levels, Mem: levels, Disk: levels, Inodes: Levels{Crit: 90}}
+}
+
 func TestFormatMount(t *testing.T) {
 	if got := formatMount("/short", 10); got != "/short" {
 		t.Fatalf("expected mount unchanged, got %q", got)
@@ -14,10 +20,15 @@ func TestFormatMount(t *testing.T) {
 	}
 }
 
-func TestTableRowWidth(t *testing.T) {
-	row := tableRow(4, 4, 2, 6, []string{"mnt", "1.0%", "🟩", "1/2GiB"})
-	if len(row) == 0 || !strings.HasPrefix(row, "│") {
-		t.Fatalf("expected table row formatting")
+func TestWriteTableHTML(t *testing.T) {
+	var b strings.Builder
+	writeTableHTML(&b, []string{"Mount", "Usage", "St"}, [][]string{{"/a&b", "1.0%", "🟩"}}, []bool{false, true, false})
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "│ Mount │ Usage │ St │") {
+		t.Fatalf("unexpected table:\n%s", b.String())
+	}
+	if lines[3] != "│ /a&amp;b  │  1.0% │ 🟩 │" {
+		t.Fatalf("unexpected row: %q", lines[3])
 	}
 }
 
@@ -27,3 +38,148 @@ func TestTableRow3Width(t *testing.T) {
 		t.Fatalf("expected table row formatting")
 	}
 }
+
+func TestStatusLabelUsesLevels(t *testing.T) {
+	levels := Levels{Warn: 60, Crit: 80}
+	if got := statusLabel(59.9, levels); got != "OK" {
+		t.Fatalf("expected OK, got %q", got)
+	}
+	if got := statusLabel(60, levels); got != "WARN" {
+		t.Fatalf("expected WARN, got %q", got)
+	}
+	if got := statusLabel(85, levels); got != "ALERT" {
+		t.Fatalf("expected ALERT, got %q", got)
+	}
+	if got := statusLabel(99, Levels{}); got != "OK" {
+		t.Fatalf("expected OK with disabled levels, got %q", got)
+	}
+}
+
+func TestFormatMetricsTextStatus(t *testing.T) {
+	metrics := Metrics{CPUPercent: 80, MemPercent: 20}
+	levels := testStatusLevels()
+	levels.CPU = Levels{Warn: 50, Crit: 70}
+	text := FormatMetricsText(metrics, levels)
+	if !strings.Contains(text, "80.0%  ALERT") {
+		t.Fatalf("expected cpu ALERT status, got:\n%s", text)
+	}
+	if !strings.Contains(text, "20.0%  OK") {
+		t.Fatalf("expected mem OK status, got:\n%s", text)
+	}
+}
+
+func TestFormatMetricsWithoutDisks(t *testing.T) {
+	metrics := Metrics{Interfaces: []NetUsage{{Name: "eth0"}}}
+	text := FormatMetricsText(metrics, testStatusLevels())
+	if !strings.Contains(text, "Disk\nnone\n\nNetwork") {
+		t.Fatalf("expected empty disk section followed by network, got:\n%s", text)
+	}
+	html := FormatMetricsHTML(metrics, testStatusLevels())
+	if !strings.Contains(html, "Disk\nnone\n\nNetwork") || !strings.HasSuffix(html, "┘</pre>") {
+		t.Fatalf("expected empty disk section followed by network, got:\n%s", html)
+	}
+}
+
+func TestDiskLevelsPerMount(t *testing.T) {
+	levels := StatusLevels{
+		Disk: Levels{Warn: 75, Crit: 90},
+		DiskMounts: []MountLevels{
+			{Pattern: "/media*", Levels: Levels{Crit: 98}},
+			{Pattern: "/boot", Levels: Levels{Crit: 80}},
+		},
+	}
+	if got := levels.DiskLevels("/"); got.Crit != 90 {
+		t.Fatalf("expected global levels for /, got %#v", got)
+	}
+	if got := levels.DiskLevels("/media/tv"); got.Crit != 98 || got.Warn != 0 {
+		t.Fatalf("expected /media* override, got %#v", got)
+	}
+	if got := levels.DiskLevels("/boot"); got.Crit != 80 {
+		t.Fatalf("expected /boot override, got %#v", got)
+	}
+}
+
+func TestFormatMetricsTextSystem(t *testing.T) {
+	metrics := Metrics{
+		CPUCores:       4,
+		SwapPercent:    25,
+		SwapUsedBytes:  1 << 30,
+		SwapTotalBytes: 4 << 30,
+		Load1:          6,
+		Load5:          4,
+		Load15:         2,
+		LoadPerCore1:   1.5,
+		Uptime:         3*86400 + 5*3600,
+		BootTime:       time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+	}
+	levels := testStatusLevels()
+	levels.Load = Levels{Warn: 1, Crit: 2}
+	text := FormatMetricsText(metrics, levels)
+	for _, want := range []string{"1.50  WARN", "Load 6.00 4.00 2.00 (4 cores)", "Swap 1.0/4.0GiB", "Uptime 3d 5h (since 2024-05-01 07:00 UTC)"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	cases := map[uint64]string{59: "0m", 3600 + 120: "1h 2m", 86400 + 7200: "1d 2h"}
+	for seconds, want := range cases {
+		if got := formatUptime(seconds); got != want {
+			t.Fatalf("formatUptime(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func TestFormatMetricsTextCPUBreakdown(t *testing.T) {
+	metrics := Metrics{
+		CPUTimes:        CPUTimes{User: 20, Nice: 5, System: 10, IOWait: 30, Steal: 12.5, IRQ: 1, SoftIRQ: 1},
+		CPUCorePercents: []float64{5, 100, 3, 4, 5, 6, 7, 8, 9},
+	}
+	levels := testStatusLevels()
+	levels.Steal = Levels{Warn: 10}
+	text := FormatMetricsText(metrics, levels)
+	for _, want := range []string{"12.5%  WARN", "CPU user 25.0% sys 10.0% iowait 30.0% steal 12.5% irq 2.0%", "Cores 0-7   5% 100%   3%", "Cores 8-8   9%"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatMetricsTextInodes(t *testing.T) {
+	metrics := Metrics{Disks: []DiskUsage{
+		{Mountpoint: "/", UsedPercent: 40, InodesTotal: 1000, InodesUsed: 960, InodesUsedPercent: 96},
+		{Mountpoint: "/btrfs", UsedPercent: 10},
+	}}
+	text := FormatMetricsText(metrics, testStatusLevels())
+	if !strings.Contains(text, "40.0%  ALERT") || !strings.Contains(text, "96.0%") {
+		t.Fatalf("expected inode usage to drive status, got:\n%s", text)
+	}
+	if !strings.Contains(text, "10.0%  OK") || !strings.Contains(text, "  -") {
+		t.Fatalf("expected placeholder for filesystems without inodes, got:\n%s", text)
+	}
+}
+
+func TestInodeLevelsPerMount(t *testing.T) {
+	levels := StatusLevels{Inodes: Levels{Crit: 90}, InodeMounts: []MountLevels{{Pattern: "/var*", Levels: Levels{Warn: 70, Crit: 80}}}}
+	if got := levels.InodeLevels("/var/lib"); got.Crit != 80 {
+		t.Fatalf("expected /var* override, got %#v", got)
+	}
+	if got := levels.InodeLevels("/"); got.Crit != 90 {
+		t.Fatalf("expected global inode levels, got %#v", got)
+	}
+}
+
+func TestMetricFields(t *testing.T) {
+	metrics := Metrics{CPUPercent: 95, MemPercent: 10, Disks: []DiskUsage{{Mountpoint: "/data", UsedPercent: 80, UsedBytes: 8 << 30, TotalBytes: 10 << 30}}}
+	fields := MetricFields(metrics, testStatusLevels())
+	if len(fields) != 3 {
+		t.Fatalf("expected cpu, mem and one disk field, got %#v", fields)
+	}
+	if fields[0] != (MetricField{Name: "CPU", Value: "95.0%", Status: "🟥 ALERT"}) || fields[1].Status != "🟩 OK" {
+		t.Fatalf("unexpected cpu/mem fields: %#v", fields[:2])
+	}
+	if fields[2] != (MetricField{Name: "Disk /data", Value: "80.0% (8.0/10.0GiB)", Status: "🟨 WARN"}) {
+		t.Fatalf("unexpected disk field: %#v", fields[2])
+	}
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+	"go.uber.org/zap"
+)
+
+type NetUsage struct {
+	Name            string  `json:"name"`
+	RxBytesPerSec   float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec   float64 `json:"tx_bytes_per_sec"`
+	RxPacketsPerSec float64 `json:"rx_packets_per_sec"`
+	TxPacketsPerSec float64 `json:"tx_packets_per_sec"`
+	RxErrors        uint64  `json:"rx_errors"`
+	TxErrors        uint64  `json:"tx_errors"`
+	RxDrops         uint64  `json:"rx_drops"`
+	TxDrops         uint64  `json:"tx_drops"`
+}
+
+func (n NetUsage) Errors() uint64 {
+	return n.RxErrors + n.TxErrors
+}
+
+func (c *Collector) sampleNet(ctx context.Context, now time.Time) {
+	counters, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		c.logger.Debug("network counters failed", zap.Error(err))
+		return
+	}
+	c.netCounters = make(map[string]net.IOCountersStat, len(counters))
+	for _, counter := range filterInterfaces(counters, c.filter) {
+		c.netCounters[counter.Name] = counter
+	}
+	c.netAt = now
+}
+
+func (c *Collector) collectNet(ctx context.Context, metrics *Metrics) {
+	prev := c.netCounters
+	prevAt := c.netAt
+	c.sampleNet(ctx, time.Now())
+	if prev == nil || !c.netAt.After(prevAt) {
+		return
+	}
+	metrics.Interfaces = netUsage(prev, c.netCounters, c.netAt.Sub(prevAt))
+}
+
+func netUsage(prev map[string]net.IOCountersStat, cur map[string]net.IOCountersStat, elapsed time.Duration) []NetUsage {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return nil
+	}
+	usage := make([]NetUsage, 0, len(cur))
+	for _, name := range slices.Sorted(maps.Keys(cur)) {
+		before, ok := prev[name]
+		if !ok {
+			continue
+		}
+		after := cur[name]
+		usage = append(usage, NetUsage{
+			Name:            name,
+			RxBytesPerSec:   float64(counterDelta(before.BytesRecv, after.BytesRecv)) / seconds,
+			TxBytesPerSec:   float64(counterDelta(before.BytesSent, after.BytesSent)) / seconds,
+			RxPacketsPerSec: float64(counterDelta(before.PacketsRecv, after.PacketsRecv)) / seconds,
+			TxPacketsPerSec: float64(counterDelta(before.PacketsSent, after.PacketsSent)) / seconds,
+			RxErrors:        counterDelta(before.Errin, after.Errin),
+			TxErrors:        counterDelta(before.Errout, after.Errout),
+			RxDrops:         counterDelta(before.Dropin, after.Dropin),
+			TxDrops:         counterDelta(before.Dropout, after.Dropout),
+		})
+	}
+	return usage
+}
+
+func counterDelta(before uint64, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
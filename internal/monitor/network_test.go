@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+func TestNetUsageDeltas(t *testing.T) {
+	prev := map[string]net.IOCountersStat{
+		"eth0": {Name: "eth0", BytesRecv: 1000, BytesSent: 500, PacketsRecv: 10, Errin: 2, Dropin: 1},
+		"wg0":  {Name: "wg0", BytesRecv: 5000},
+	}
+	cur := map[string]net.IOCountersStat{
+		"eth0": {Name: "eth0", BytesRecv: 21000, BytesSent: 10500, PacketsRecv: 30, Errin: 5, Dropin: 1},
+		"wg0":  {Name: "wg0", BytesRecv: 100},
+		"eth1": {Name: "eth1", BytesRecv: 100},
+	}
+	usage := netUsage(prev, cur, 10*time.Second)
+	if len(usage) != 2 || usage[0].Name != "eth0" || usage[1].Name != "wg0" {
+		t.Fatalf("expected eth0 and wg0 sorted, got %#v", usage)
+	}
+	eth0 := usage[0]
+	if eth0.RxBytesPerSec != 2000 || eth0.TxBytesPerSec != 1000 || eth0.RxPacketsPerSec != 2 {
+		t.Fatalf("unexpected eth0 rates: %#v", eth0)
+	}
+	if eth0.RxErrors != 3 || eth0.Errors() != 3 || eth0.RxDrops != 0 {
+		t.Fatalf("unexpected eth0 errors: %#v", eth0)
+	}
+	if usage[1].RxBytesPerSec != 0 {
+		t.Fatalf("expected counter reset to count as zero, got %#v", usage[1])
+	}
+}
+
+func TestFilterInterfaces(t *testing.T) {
+	counters := []net.IOCountersStat{{Name: "lo"}, {Name: "eth0"}, {Name: "veth12ab"}}
+	filtered := filterInterfaces(counters, FilterConfig{IfaceExclude: []string{"lo", "veth*"}})
+	if len(filtered) != 1 || filtered[0].Name != "eth0" {
+		t.Fatalf("expected only eth0, got %#v", filtered)
+	}
+	filtered = filterInterfaces(counters, FilterConfig{IfaceInclude: []string{"lo"}, IfaceExclude: []string{"lo"}})
+	if len(filtered) != 1 || filtered[0].Name != "lo" {
+		t.Fatalf("expected include to override exclude, got %#v", filtered)
+	}
+}
+
+func TestFormatMetricsTextNetwork(t *testing.T) {
+	metrics := Metrics{Interfaces: []NetUsage{{Name: "eth0", RxBytesPerSec: 12.5e6, TxBytesPerSec: 1000, RxErrors: 2, RxDrops: 1}}}
+	text := FormatMetricsText(metrics, testStatusLevels())
+	for _, want := range []string{"Network", "eth0", "100.0Mbit/s", "8.0kbit/s", "2/1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report, got:\n%s", want, text)
+		}
+	}
+	if html := FormatMetricsHTML(metrics, testStatusLevels()); !strings.Contains(html, "100.0Mbit") {
+		t.Fatalf("expected network table in html report, got:\n%s", html)
+	}
+}
@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type PressureAvg struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+}
+
+type PressureStat struct {
+	Some PressureAvg `json:"some"`
+	Full PressureAvg `json:"full"`
+}
+
+type Pressure struct {
+	Available bool         `json:"available"`
+	CPU       PressureStat `json:"cpu"`
+	Memory    PressureStat `json:"memory"`
+	IO        PressureStat `json:"io"`
+}
+
+func (a PressureAvg) Window(seconds int) float64 {
+	switch seconds {
+	case 10:
+		return a.Avg10
+	case 300:
+		return a.Avg300
+	default:
+		return a.Avg60
+	}
+}
+
+func readPressure(root string) (Pressure, error) {
+	pressure := Pressure{}
+	for _, res := range []struct {
+		name string
+		stat *PressureStat
+	}{
+		{"cpu", &pressure.CPU},
+		{"memory", &pressure.Memory},
+		{"io", &pressure.IO},
+	} {
+		f, err := os.Open(filepath.Join(root, "pressure", res.name))
+		if err != nil {
+			return Pressure{}, err
+		}
+		stat, err := parsePressure(f)
+		_ = f.Close()
+		if err != nil {
+			return Pressure{}, fmt.Errorf("pressure %s: %w", res.name, err)
+		}
+		*res.stat = stat
+	}
+	pressure.Available = true
+	return pressure, nil
+}
+
+func parsePressure(r io.Reader) (PressureStat, error) {
+	stat := PressureStat{}
+	found := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var avg *PressureAvg
+		switch fields[0] {
+		case "some":
+			avg = &stat.Some
+			found = true
+		case "full":
+			avg = &stat.Full
+		default:
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, raw, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return PressureStat{}, fmt.Errorf("invalid %s value %q", key, raw)
+			}
+			switch key {
+			case "avg10":
+				avg.Avg10 = value
+			case "avg60":
+				avg.Avg60 = value
+			case "avg300":
+				avg.Avg300 = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return PressureStat{}, err
+	}
+	if !found {
+		return PressureStat{}, fmt.Errorf("missing some line")
+	}
+	return stat, nil
+}
+
+func formatPressureLine(p Pressure) string {
+	avg := func(a PressureAvg) string {
+		return fmt.Sprintf("%.1f/%.1f/%.1f%%", a.Avg10, a.Avg60, a.Avg300)
+	}
+	return fmt.Sprintf("Pressure cpu %s mem %s io %s (some avg10/60/300)", avg(p.CPU.Some), avg(p.Memory.Some), avg(p.IO.Some))
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePressure(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, "pressure")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadPressure(t *testing.T) {
+	root := t.TempDir()
+	writePressure(t, root, map[string]string{
+		"cpu":    "some avg10=12.50 avg60=8.25 avg300=2.00 total=39674774\n",
+		"memory": "some avg10=0.00 avg60=1.00 avg300=0.50 total=10\nfull avg10=0.00 avg60=0.75 avg300=0.25 total=5\n",
+		"io":     "some avg10=40.00 avg60=30.00 avg300=20.00 total=2506435\nfull avg10=35.00 avg60=25.00 avg300=15.00 total=2053688\n",
+	})
+	pressure, err := readPressure(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pressure.Available || pressure.CPU.Some.Avg10 != 12.5 || pressure.Memory.Full.Avg60 != 0.75 || pressure.IO.Some.Avg300 != 20 {
+		t.Fatalf("unexpected pressure: %#v", pressure)
+	}
+	if got := pressure.IO.Full.Window(10); got != 35 {
+		t.Fatalf("expected avg10 window, got %.2f", got)
+	}
+	if got := pressure.CPU.Some.Window(0); got != 8.25 {
+		t.Fatalf("expected avg60 as default window, got %.2f", got)
+	}
+	if got := formatPressureLine(pressure); got != "Pressure cpu 12.5/8.2/2.0% mem 0.0/1.0/0.5% io 40.0/30.0/20.0% (some avg10/60/300)" {
+		t.Fatalf("unexpected pressure line: %q", got)
+	}
+}
+
+func TestReadPressureUnavailable(t *testing.T) {
+	root := t.TempDir()
+	if pressure, err := readPressure(root); err == nil || pressure.Available {
+		t.Fatalf("expected missing pressure files to be unavailable, got %#v", pressure)
+	}
+	writePressure(t, root, map[string]string{"cpu": "some avg10=x\n", "memory": "", "io": ""})
+	if _, err := readPressure(root); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestParsePressureRequiresSome(t *testing.T) {
+	if _, err := parsePressure(strings.NewReader("full avg10=1.00 avg60=1.00 avg300=1.00 total=1\n")); err == nil {
+		t.Fatalf("expected error without some line")
+	}
+}
@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"go.uber.org/zap"
+
+	"github.com/zergo0/simple-system-monitor/internal/units"
+)
+
+const processSampleInterval = 500 * time.Millisecond
+
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user"`
+	Cmdline    string  `json:"cmdline"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+}
+
+type TopProcesses struct {
+	ByCPU []ProcessInfo `json:"by_cpu"`
+	ByMem []ProcessInfo `json:"by_mem"`
+}
+
+type processSample struct {
+	proc    *process.Process
+	info    ProcessInfo
+	cpuTime float64
+}
+
+func CollectTopProcesses(ctx context.Context, logger *zap.Logger, n int) (TopProcesses, error) {
+	if n <= 0 {
+		return TopProcesses{}, nil
+	}
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return TopProcesses{}, err
+	}
+	samples := make([]*processSample, 0, len(procs))
+	for _, proc := range procs {
+		times, err := proc.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, &processSample{proc: proc, info: ProcessInfo{PID: proc.Pid}, cpuTime: times.User + times.System})
+	}
+
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		return TopProcesses{}, ctx.Err()
+	case <-time.After(processSampleInterval):
+	}
+	elapsed := time.Since(start).Seconds()
+
+	alive := samples[:0]
+	for _, sample := range samples {
+		times, err := sample.proc.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		sample.info.CPUPercent = max(times.User+times.System-sample.cpuTime, 0) / elapsed * 100
+		if mem, err := sample.proc.MemoryInfoWithContext(ctx); err == nil {
+			sample.info.RSSBytes = mem.RSS
+		}
+		alive = append(alive, sample)
+	}
+
+	top := TopProcesses{
+		ByCPU: topSamples(alive, n, func(a, b ProcessInfo) bool { return a.CPUPercent > b.CPUPercent }),
+		ByMem: topSamples(alive, n, func(a, b ProcessInfo) bool { return a.RSSBytes > b.RSSBytes }),
+	}
+	details := make(map[int32]ProcessInfo)
+	for _, list := range [][]ProcessInfo{top.ByCPU, top.ByMem} {
+		for i := range list {
+			info, ok := details[list[i].PID]
+			if !ok {
+				info = describeProcess(ctx, logger, list[i])
+				details[list[i].PID] = info
+			}
+			list[i] = info
+		}
+	}
+	return top, nil
+}
+
+func topSamples(samples []*processSample, n int, less func(a, b ProcessInfo) bool) []ProcessInfo {
+	infos := make([]ProcessInfo, 0, len(samples))
+	for _, sample := range samples {
+		infos = append(infos, sample.info)
+	}
+	return topProcesses(infos, n, less)
+}
+
+func topProcesses(infos []ProcessInfo, n int, less func(a, b ProcessInfo) bool) []ProcessInfo {
+	sorted := append([]ProcessInfo(nil), infos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if less(sorted[i], sorted[j]) {
+			return true
+		}
+		if less(sorted[j], sorted[i]) {
+			return false
+		}
+		return sorted[i].PID < sorted[j].PID
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+func describeProcess(ctx context.Context, logger *zap.Logger, info ProcessInfo) ProcessInfo {
+	proc, err := process.NewProcessWithContext(ctx, info.PID)
+	if err != nil {
+		logger.Debug("process lookup failed", zap.Int32("pid", info.PID), zap.Error(err))
+		return info
+	}
+	info.Name, _ = proc.NameWithContext(ctx)
+	info.User, _ = proc.UsernameWithContext(ctx)
+	info.Cmdline, _ = proc.CmdlineWithContext(ctx)
+	return info
+}
+
+func FormatProcessesText(top TopProcesses) string {
+	lines := []string{}
+	if len(top.ByCPU) > 0 {
+		lines = append(lines, "", "Top CPU")
+		lines = append(lines, formatTableLines(processHeader, processRows(top.ByCPU), processAlign)...)
+	}
+	if len(top.ByMem) > 0 {
+		lines = append(lines, "", "Top memory")
+		lines = append(lines, formatTableLines(processHeader, processRows(top.ByMem), processAlign)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	processHeader = []string{"PID", "User", "CPU", "RSS", "Command"}
+	processAlign  = []bool{true, false, true, true, false}
+)
+
+func processRows(infos []ProcessInfo) [][]string {
+	rows := make([][]string, 0, len(infos))
+	for _, info := range infos {
+		command := info.Cmdline
+		if command == "" {
+			command = info.Name
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", info.PID),
+			formatMountPlain(CleanText(info.User), 12),
+			fmt.Sprintf("%.1f%%", info.CPUPercent),
+			units.Bytes(float64(info.RSSBytes)),
+			formatMountPlain(CleanText(command), 48),
+		})
+	}
+	return rows
+}
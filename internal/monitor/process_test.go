@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopProcessesOrder(t *testing.T) {
+	infos := []ProcessInfo{
+		{PID: 30, CPUPercent: 5, RSSBytes: 100},
+		{PID: 10, CPUPercent: 50, RSSBytes: 10},
+		{PID: 20, CPUPercent: 5, RSSBytes: 300},
+		{PID: 40, CPUPercent: 1, RSSBytes: 200},
+	}
+	byCPU := topProcesses(infos, 3, func(a, b ProcessInfo) bool { return a.CPUPercent > b.CPUPercent })
+	if len(byCPU) != 3 || byCPU[0].PID != 10 || byCPU[1].PID != 20 || byCPU[2].PID != 30 {
+		t.Fatalf("unexpected cpu order: %#v", byCPU)
+	}
+	byMem := topProcesses(infos, 10, func(a, b ProcessInfo) bool { return a.RSSBytes > b.RSSBytes })
+	if len(byMem) != 4 || byMem[0].PID != 20 || byMem[3].PID != 10 {
+		t.Fatalf("unexpected memory order: %#v", byMem)
+	}
+	if infos[0].PID != 30 {
+		t.Fatalf("input slice was reordered: %#v", infos)
+	}
+}
+
+func TestFormatProcessesText(t *testing.T) {
+	top := TopProcesses{
+		ByCPU: []ProcessInfo{{PID: 42, Name: "stress", User: "root", Cmdline: "stress --cpu\t8", CPUPercent: 398.5, RSSBytes: 3 << 20}},
+		ByMem: []ProcessInfo{{PID: 7, Name: "postgres", User: "postgres", RSSBytes: 2 << 30}},
+	}
+	text := FormatProcessesText(top)
+	for _, want := range []string{"Top CPU", "42  root  398.5%  3.0MiB  stress --cpu 8", "Top memory", "2.0GiB  postgres"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in:\n%s", want, text)
+		}
+	}
+	if FormatProcessesText(TopProcesses{}) != "" {
+		t.Fatalf("expected empty text without processes")
+	}
+}
@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"go.uber.org/zap"
+)
+
+type ProcessCheck struct {
+	Name        string
+	ProcessName string
+	Cmdline     *regexp.Regexp
+	PIDFile     string
+	Min         int
+	Max         int
+	Restart     bool
+}
+
+type ProcessStatus struct {
+	Name     string  `json:"name"`
+	Count    int     `json:"count"`
+	PIDs     []int32 `json:"pids"`
+	Restarts int     `json:"restarts"`
+	Min      int     `json:"min"`
+	Max      int     `json:"max,omitempty"`
+}
+
+type processEntry struct {
+	pid     int32
+	ppid    int32
+	name    string
+	cmdline string
+}
+
+func (c *Collector) collectProcesses(ctx context.Context, metrics *Metrics) {
+	if len(c.processChecks) == 0 {
+		return
+	}
+	entries := c.listProcesses(ctx)
+	if c.processMain == nil {
+		c.processMain = make(map[string]int32, len(c.processChecks))
+	}
+	metrics.Processes = make([]ProcessStatus, 0, len(c.processChecks))
+	for _, check := range c.processChecks {
+		status := ProcessStatus{Name: check.Name, Min: check.Min, Max: check.Max}
+		if check.PIDFile != "" {
+			status.PIDs = pidFileProcess(ctx, c.logger, check.PIDFile)
+		} else {
+			status.PIDs = matchProcesses(check, entries)
+		}
+		status.Count = len(status.PIDs)
+		if check.Restart {
+			main := mainProcess(status.PIDs, entries)
+			if prev, ok := c.processMain[check.Name]; ok && main != 0 && main != prev {
+				status.Restarts = 1
+			}
+			if main != 0 {
+				c.processMain[check.Name] = main
+			}
+		}
+		metrics.Processes = append(metrics.Processes, status)
+	}
+}
+
+func (c *Collector) listProcesses(ctx context.Context) []processEntry {
+	needName, needCmdline, needParent := false, false, false
+	for _, check := range c.processChecks {
+		needName = needName || check.ProcessName != ""
+		needCmdline = needCmdline || check.Cmdline != nil
+		needParent = needParent || (check.Restart && check.PIDFile == "")
+	}
+	if !needName && !needCmdline {
+		return nil
+	}
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		c.logger.Debug("process list failed", zap.Error(err))
+		return nil
+	}
+	self := int32(os.Getpid())
+	entries := make([]processEntry, 0, len(procs))
+	for _, proc := range procs {
+		if proc.Pid == self {
+			continue
+		}
+		entry := processEntry{pid: proc.Pid}
+		if needName {
+			entry.name, _ = proc.NameWithContext(ctx)
+		}
+		if needCmdline {
+			entry.cmdline, _ = proc.CmdlineWithContext(ctx)
+		}
+		if needParent {
+			entry.ppid, _ = proc.PpidWithContext(ctx)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func matchProcesses(check ProcessCheck, entries []processEntry) []int32 {
+	pids := []int32{}
+	for _, entry := range entries {
+		switch {
+		case check.ProcessName != "" && entry.name == check.ProcessName:
+		case check.Cmdline != nil && entry.cmdline != "" && check.Cmdline.MatchString(entry.cmdline):
+		default:
+			continue
+		}
+		pids = append(pids, entry.pid)
+	}
+	slices.Sort(pids)
+	return pids
+}
+
+func pidFileProcess(ctx context.Context, logger *zap.Logger, path string) []int32 {
+	pid, err := readPIDFile(path)
+	if err != nil {
+		logger.Debug("pidfile read failed", zap.String("path", path), zap.Error(err))
+		return []int32{}
+	}
+	if exists, err := process.PidExistsWithContext(ctx, pid); err != nil || !exists {
+		return []int32{}
+	}
+	return []int32{pid}
+}
+
+func readPIDFile(path string) (int32, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if pid <= 0 {
+		return 0, fmt.Errorf("invalid pid %d", pid)
+	}
+	return int32(pid), nil
+}
+
+func mainProcess(pids []int32, entries []processEntry) int32 {
+	parents := make(map[int32]int32, len(entries))
+	for _, entry := range entries {
+		parents[entry.pid] = entry.ppid
+	}
+	main := int32(0)
+	for _, pid := range pids {
+		if slices.Contains(pids, parents[pid]) {
+			continue
+		}
+		if main == 0 || pid < main {
+			main = pid
+		}
+	}
+	return main
+}
+
+func processStatusRank(p ProcessStatus) int {
+	switch {
+	case p.Count < p.Min:
+		return 2
+	case p.Max > 0 && p.Count > p.Max:
+		return 1
+	case p.Restarts > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+var processCheckAlign = []bool{false, true, false, false}
+
+func processCheckRows(processes []ProcessStatus, status []string) [][]string {
+	rows := make([][]string, 0, len(processes))
+	for _, p := range processes {
+		pids := make([]string, 0, len(p.PIDs))
+		for _, pid := range p.PIDs {
+			pids = append(pids, strconv.Itoa(int(pid)))
+		}
+		pidText := "-"
+		if len(pids) > 0 {
+			pidText = formatMountPlain(strings.Join(pids, ","), 24)
+		}
+		count := strconv.Itoa(p.Count)
+		if p.Max > 0 {
+			count = fmt.Sprintf("%d (%d-%d)", p.Count, p.Min, p.Max)
+		} else if p.Min != 1 {
+			count = fmt.Sprintf("%d (>=%d)", p.Count, p.Min)
+		}
+		rows = append(rows, []string{formatMountPlain(CleanText(p.Name), 24), count, pidText, status[processStatusRank(p)]})
+	}
+	return rows
+}
+
+func formatProcessCheckLines(processes []ProcessStatus) []string {
+	if len(processes) == 0 {
+		return nil
+	}
+	lines := []string{"", "Processes"}
+	lines = append(lines, formatTableLines([]string{"Process", "Count", "PID", "Status"}, processCheckRows(processes, statusLabels), processCheckAlign)...)
+	return lines
+}
+
+func writeProcessCheckHTML(b *strings.Builder, processes []ProcessStatus) {
+	if len(processes) == 0 {
+		return
+	}
+	b.WriteString("\n\nProcesses\n")
+	writeTableHTML(b, []string{"Process", "Count", "PID", "St"}, processCheckRows(processes, statusEmojis), processCheckAlign)
+}
@@ -0,0 +1,127 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestMatchProcesses(t *testing.T) {
+	entries := []processEntry{
+		{pid: 30, name: "nginx", cmdline: "nginx: worker process"},
+		{pid: 12, name: "nginx", cmdline: "nginx: master process /usr/sbin/nginx"},
+		{pid: 40, name: "postgres", cmdline: "/usr/lib/postgresql/16/bin/postgres -D /var/lib/postgresql"},
+		{pid: 50, name: "zombie"},
+	}
+	if got := matchProcesses(ProcessCheck{ProcessName: "nginx"}, entries); !slices.Equal(got, []int32{12, 30}) {
+		t.Fatalf("unexpected name matches: %v", got)
+	}
+	if got := matchProcesses(ProcessCheck{Cmdline: regexp.MustCompile(`^nginx: master`)}, entries); !slices.Equal(got, []int32{12}) {
+		t.Fatalf("unexpected cmdline matches: %v", got)
+	}
+	if got := matchProcesses(ProcessCheck{Cmdline: regexp.MustCompile(`.*`)}, entries); len(got) != 3 {
+		t.Fatalf("expected processes without cmdline to be skipped, got %v", got)
+	}
+	if got := matchProcesses(ProcessCheck{ProcessName: "redis"}, entries); got == nil || len(got) != 0 {
+		t.Fatalf("expected empty non-nil match list, got %#v", got)
+	}
+}
+
+func TestReadPIDFile(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "app.pid")
+	if err := os.WriteFile(valid, []byte("1234\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := readPIDFile(valid); err != nil || pid != 1234 {
+		t.Fatalf("readPIDFile = %d, %v", pid, err)
+	}
+	invalid := filepath.Join(dir, "bad.pid")
+	if err := os.WriteFile(invalid, []byte("0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPIDFile(invalid); err == nil {
+		t.Fatalf("expected error for pid 0")
+	}
+	if _, err := readPIDFile(filepath.Join(dir, "missing.pid")); err == nil {
+		t.Fatalf("expected error for missing pidfile")
+	}
+}
+
+func TestPIDFileProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "self.pid")
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := pidFileProcess(context.Background(), zap.NewNop(), path); len(got) != 1 || int(got[0]) != os.Getpid() {
+		t.Fatalf("expected own pid from pidfile, got %v", got)
+	}
+}
+
+func TestMainProcess(t *testing.T) {
+	entries := []processEntry{
+		{pid: 12, ppid: 1},
+		{pid: 30, ppid: 12},
+		{pid: 31, ppid: 12},
+		{pid: 99, ppid: 1},
+	}
+	if got := mainProcess([]int32{12, 30, 31}, entries); got != 12 {
+		t.Fatalf("expected the master pid, got %d", got)
+	}
+	if got := mainProcess([]int32{30, 31, 40}, entries); got != 30 {
+		t.Fatalf("expected the lowest root pid, got %d", got)
+	}
+	if got := mainProcess([]int32{77}, nil); got != 77 {
+		t.Fatalf("expected pidfile pid, got %d", got)
+	}
+	if got := mainProcess([]int32{}, entries); got != 0 {
+		t.Fatalf("expected 0 without matches, got %d", got)
+	}
+}
+
+func TestCollectProcessesRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.pid")
+	c := NewCollector(zap.NewNop(), "host", FilterConfig{}, Sources{}, []ProcessCheck{{Name: "app", PIDFile: path, Min: 1, Restart: true}})
+	collect := func(pid int) ProcessStatus {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(strconv.Itoa(pid)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var metrics Metrics
+		c.collectProcesses(context.Background(), &metrics)
+		return metrics.Processes[0]
+	}
+	if status := collect(os.Getpid()); status.Restarts != 0 || status.Count != 1 {
+		t.Fatalf("unexpected first status: %#v", status)
+	}
+	if status := collect(os.Getpid()); status.Restarts != 0 {
+		t.Fatalf("expected no restart for the same pid, got %#v", status)
+	}
+	if status := collect(os.Getppid()); status.Restarts != 1 {
+		t.Fatalf("expected restart after the pidfile changed, got %#v", status)
+	}
+	if status := collect(os.Getppid()); status.Restarts != 0 {
+		t.Fatalf("expected restart to be reported once, got %#v", status)
+	}
+}
+
+func TestFormatMetricsTextProcesses(t *testing.T) {
+	metrics := Metrics{Processes: []ProcessStatus{
+		{Name: "db", Count: 0, PIDs: []int32{}, Min: 1},
+		{Name: "nginx", Count: 6, PIDs: []int32{1, 2, 3, 4, 5, 6}, Min: 1, Max: 5},
+		{Name: "app", Count: 1, PIDs: []int32{77}, Min: 1},
+	}}
+	text := FormatMetricsText(metrics, testStatusLevels())
+	for _, want := range []string{"Processes", "db             0  -            ALERT", "nginx    6 (1-5)  1,2,3,4,5,6  WARN", "app            1  77           OK"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Temperature struct {
+	Sensor   string  `json:"sensor"`
+	Celsius  float64 `json:"celsius"`
+	High     float64 `json:"high,omitempty"`
+	Critical float64 `json:"critical,omitempty"`
+}
+
+func readTemperatures(root string, filter FilterConfig) []Temperature {
+	temps := append(readHwmon(root), readThermalZones(root)...)
+	filtered := make([]Temperature, 0, len(temps))
+	for _, temp := range temps {
+		if len(filter.SensorInclude) > 0 {
+			if !matchMount(filter.SensorInclude, temp.Sensor) {
+				continue
+			}
+		} else if matchMount(filter.SensorExclude, temp.Sensor) {
+			continue
+		}
+		filtered = append(filtered, temp)
+	}
+	return filtered
+}
+
+func readHwmon(root string) []Temperature {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "hwmon", "hwmon*"))
+	sortNatural(dirs)
+	temps := []Temperature{}
+	seen := make(map[string]int)
+	for _, dir := range dirs {
+		chip := readSysfsString(filepath.Join(dir, "name"))
+		if chip == "" {
+			chip = filepath.Base(dir)
+		}
+		seen[chip]++
+		if seen[chip] > 1 {
+			chip = fmt.Sprintf("%s.%d", chip, seen[chip]-1)
+		}
+		inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+		sortNatural(inputs)
+		for _, input := range inputs {
+			celsius, ok := readMilliCelsius(input)
+			if !ok {
+				continue
+			}
+			prefix := strings.TrimSuffix(input, "_input")
+			label := readSysfsString(prefix + "_label")
+			if label == "" {
+				label = filepath.Base(prefix)
+			}
+			temp := Temperature{Sensor: chip + "/" + label, Celsius: celsius}
+			temp.High, _ = readMilliCelsius(prefix + "_max")
+			temp.Critical, _ = readMilliCelsius(prefix + "_crit")
+			temps = append(temps, temp)
+		}
+	}
+	return temps
+}
+
+func readThermalZones(root string) []Temperature {
+	zones, _ := filepath.Glob(filepath.Join(root, "class", "thermal", "thermal_zone*"))
+	sortNatural(zones)
+	temps := []Temperature{}
+	for _, zone := range zones {
+		celsius, ok := readMilliCelsius(filepath.Join(zone, "temp"))
+		if !ok {
+			continue
+		}
+		name := filepath.Base(zone)
+		if kind := readSysfsString(filepath.Join(zone, "type")); kind != "" {
+			name += "/" + kind
+		}
+		temps = append(temps, Temperature{Sensor: name, Celsius: celsius})
+	}
+	return temps
+}
+
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readMilliCelsius(path string) (float64, bool) {
+	value, err := strconv.ParseInt(readSysfsString(path), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return float64(value) / 1000, true
+}
+
+func sortNatural(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := paths[i], paths[j]
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+}
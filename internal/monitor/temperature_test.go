@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+}
+
+func TestReadTemperatures(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, map[string]string{
+		"class/hwmon/hwmon0/name":          "coretemp\n",
+		"class/hwmon/hwmon0/temp1_input":   "54000\n",
+		"class/hwmon/hwmon0/temp1_label":   "Package id 0\n",
+		"class/hwmon/hwmon0/temp1_max":     "80000\n",
+		"class/hwmon/hwmon0/temp1_crit":    "100000\n",
+		"class/hwmon/hwmon0/temp10_input":  "41500\n",
+		"class/hwmon/hwmon0/temp2_input":   "48000\n",
+		"class/hwmon/hwmon1/name":          "nvme\n",
+		"class/hwmon/hwmon1/temp1_input":   "38850\n",
+		"class/hwmon/hwmon10/name":         "nvme\n",
+		"class/hwmon/hwmon10/temp1_input":  "bogus\n",
+		"class/hwmon/hwmon2/name":          "nvme\n",
+		"class/hwmon/hwmon2/temp1_input":   "40000\n",
+		"class/thermal/thermal_zone0/type": "x86_pkg_temp\n",
+		"class/thermal/thermal_zone0/temp": "55000\n",
+		"class/thermal/thermal_zone1/temp": "30000\n",
+	})
+
+	temps := readTemperatures(root, FilterConfig{})
+	var names []string
+	for _, temp := range temps {
+		names = append(names, temp.Sensor)
+	}
+	want := "coretemp/Package id 0,coretemp/temp2,coretemp/temp10,nvme/temp1,nvme.1/temp1,thermal_zone0/x86_pkg_temp,thermal_zone1"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("unexpected sensors:\n got %s\nwant %s", got, want)
+	}
+	if temps[0].Celsius != 54 || temps[0].High != 80 || temps[0].Critical != 100 {
+		t.Fatalf("unexpected package reading: %#v", temps[0])
+	}
+	if temps[3].Celsius != 38.85 {
+		t.Fatalf("unexpected nvme reading: %#v", temps[3])
+	}
+
+	temps = readTemperatures(root, FilterConfig{SensorExclude: []string{"thermal_zone*", "nvme*"}})
+	if len(temps) != 3 {
+		t.Fatalf("expected exclude to drop zones and nvme, got %#v", temps)
+	}
+	temps = readTemperatures(root, FilterConfig{SensorInclude: []string{"coretemp/Package id 0"}})
+	if len(temps) != 1 {
+		t.Fatalf("expected include to keep only the package sensor, got %#v", temps)
+	}
+}
+
+func TestReadTemperaturesMissingTree(t *testing.T) {
+	if temps := readTemperatures(t.TempDir(), FilterConfig{}); len(temps) != 0 {
+		t.Fatalf("expected no sensors, got %#v", temps)
+	}
+}
+
+func TestFormatMetricsTextTemperature(t *testing.T) {
+	metrics := Metrics{Temperatures: []Temperature{{Sensor: "coretemp/Package id 0", Celsius: 91}}}
+	levels := testStatusLevels()
+	levels.Temp = Levels{Warn: 70, Crit: 90}
+	text := FormatMetricsText(metrics, levels)
+	if !strings.Contains(text, "Temperature") || !strings.Contains(text, "91.0°C  ALERT") {
+		t.Fatalf("expected temperature section, got:\n%s", text)
+	}
+}
@@ -0,0 +1,285 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+const (
+	DiscordColorCritical = 0xd32f2f
+	DiscordColorWarning  = 0xf9a825
+	DiscordColorResolved = 0x2e7d32
+	DiscordColorReport   = 0x1e88e5
+
+	discordMaxFields      = 25
+	discordMaxDescription = 4096
+)
+
+type DiscordConfig struct {
+	URL      string
+	Username string
+	Timeout  time.Duration
+	Retries  int
+}
+
+type Discord struct {
+	cfg     DiscordConfig
+	client  *http.Client
+	maxWait time.Duration
+
+	mu      sync.Mutex
+	resetAt time.Time
+}
+
+type DiscordPayload struct {
+	Username    string              `json:"username,omitempty"`
+	Embeds      []DiscordEmbed      `json:"embeds"`
+	Attachments []DiscordAttachment `json:"attachments,omitempty"`
+}
+
+type DiscordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Image       *DiscordEmbedImage  `json:"image,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type DiscordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+type DiscordAttachment struct {
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+}
+
+func NewDiscord(cfg DiscordConfig, httpClient *http.Client) *Discord {
+	if cfg.URL == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Discord{cfg: cfg, client: httpClient, maxWait: time.Minute}
+}
+
+func (d *Discord) Name() string {
+	return "discord"
+}
+
+func (d *Discord) Notify(ctx context.Context, msg Message) error {
+	image, err := msg.PNG()
+	if err != nil {
+		return err
+	}
+	payload := discordPayload(msg)
+	payload.Username = d.cfg.Username
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("payload_json", string(payloadJSON)); err != nil {
+		return err
+	}
+	part, err := writer.CreateFormFile("files[0]", msg.Filename())
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(image); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= d.cfg.Retries; attempt++ {
+		if err := d.waitRateLimit(ctx); err != nil {
+			return err
+		}
+		retryAfter, err := d.post(ctx, writer.FormDataContentType(), buf.Bytes())
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if retryAfter < 0 {
+			break
+		}
+		d.delay(retryAfter)
+	}
+	return lastErr
+}
+
+func discordPayload(msg Message) DiscordPayload {
+	embed := DiscordEmbed{
+		Title:  truncateRunes(msg.Title(), 256),
+		Color:  discordColor(msg),
+		Image:  &DiscordEmbedImage{URL: "attachment://" + msg.Filename()},
+		Fields: []DiscordEmbedField{},
+	}
+	if msg.Type != TypeReport && len(msg.Events) > 0 {
+		lines := make([]string, 0, len(msg.Events))
+		for _, event := range msg.Events {
+			lines = append(lines, monitor.CleanText(event.Text()))
+		}
+		embed.Description = truncateRunes(strings.Join(lines, "\n"), discordMaxDescription)
+	}
+	for _, field := range monitor.MetricFields(msg.Metrics, msg.Levels) {
+		if len(embed.Fields) == discordMaxFields {
+			break
+		}
+		embed.Fields = append(embed.Fields, DiscordEmbedField{
+			Name:   truncateRunes(field.Name, 256),
+			Value:  field.Value + "\n" + field.Status,
+			Inline: true,
+		})
+	}
+	if msg.SystemName != "" {
+		embed.Footer = &DiscordEmbedFooter{Text: monitor.CleanText(msg.SystemName)}
+	}
+	if !msg.At.IsZero() {
+		embed.Timestamp = msg.At.UTC().Format(time.RFC3339)
+	}
+	return DiscordPayload{Embeds: []DiscordEmbed{embed}, Attachments: []DiscordAttachment{{ID: 0, Filename: msg.Filename()}}}
+}
+
+func discordColor(msg Message) int {
+	switch {
+	case msg.Type == TypeReport:
+		return DiscordColorReport
+	case msg.Type == TypeResolved:
+		return DiscordColorResolved
+	case msg.Severity() == alerts.SeverityCritical:
+		return DiscordColorCritical
+	default:
+		return DiscordColorWarning
+	}
+}
+
+func (d *Discord) post(ctx context.Context, contentType string, body []byte) (time.Duration, error) {
+	endpoint, err := url.Parse(d.cfg.URL)
+	if err != nil {
+		return -1, err
+	}
+	query := endpoint.Query()
+	query.Set("wait", "true")
+	endpoint.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "simple-system-monitor")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	d.updateRateLimit(resp.Header)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return discordRetryAfter(resp.Header, respBody), fmt.Errorf("discord rate limited")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return -1, fmt.Errorf("discord status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return -1, nil
+}
+
+func (d *Discord) updateRateLimit(header http.Header) {
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	if resetAfter, ok := parseSeconds(header.Get("X-RateLimit-Reset-After")); ok {
+		d.delay(resetAfter)
+	}
+}
+
+func (d *Discord) delay(wait time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if until := time.Now().Add(min(wait, d.maxWait)); until.After(d.resetAt) {
+		d.resetAt = until
+	}
+}
+
+func (d *Discord) waitRateLimit(ctx context.Context) error {
+	d.mu.Lock()
+	wait := time.Until(d.resetAt)
+	d.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return fmt.Errorf("discord rate limited for %s", wait.Round(time.Millisecond))
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+func discordRetryAfter(header http.Header, body []byte) time.Duration {
+	var payload struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.RetryAfter > 0 {
+		return time.Duration(payload.RetryAfter * float64(time.Second))
+	}
+	if wait, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+		return wait
+	}
+	if wait, ok := parseSeconds(header.Get("X-RateLimit-Reset-After")); ok {
+		return wait
+	}
+	return time.Second
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+func TestDiscordPostsEmbedWithImage(t *testing.T) {
+	var (
+		payload DiscordPayload
+		image   []byte
+		name    string
+		query   string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("invalid multipart body: %v", err)
+			return
+		}
+		if err := json.Unmarshal([]byte(r.FormValue("payload_json")), &payload); err != nil {
+			t.Errorf("invalid payload_json: %v", err)
+		}
+		file, header, err := r.FormFile("files[0]")
+		if err != nil {
+			t.Errorf("missing file: %v", err)
+			return
+		}
+		defer file.Close()
+		name = header.Filename
+		image, _ = io.ReadAll(file)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+
+	discord := NewDiscord(DiscordConfig{URL: server.URL + "?thread_id=7", Username: "monitor"}, server.Client())
+	msg := Message{
+		Type:       TypeReport,
+		SystemName: "Web",
+		Metrics:    monitor.Metrics{Hostname: "web-1", CPUPercent: 95, MemPercent: 10, Disks: []monitor.DiskUsage{{Mountpoint: "/", UsedPercent: 50}, {Mountpoint: "/data", UsedPercent: 80}}},
+		Levels:     testStatusLevels(),
+		At:         time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if err := discord.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	if query != "thread_id=7&wait=true" {
+		t.Fatalf("unexpected query %q", query)
+	}
+	if name != "metrics.png" || !bytes.HasPrefix(image, []byte("\x89PNG")) {
+		t.Fatalf("expected png attachment, got %q (%d bytes)", name, len(image))
+	}
+	if payload.Username != "monitor" || len(payload.Embeds) != 1 || len(payload.Attachments) != 1 || payload.Attachments[0].Filename != "metrics.png" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "Simple System Monitor - web-1" || embed.Color != DiscordColorReport || embed.Image.URL != "attachment://metrics.png" {
+		t.Fatalf("unexpected embed: %+v", embed)
+	}
+	if embed.Footer == nil || embed.Footer.Text != "Web" || embed.Timestamp != "2024-05-01T12:00:00Z" {
+		t.Fatalf("unexpected footer or timestamp: %+v", embed)
+	}
+	if len(embed.Fields) != 4 || embed.Fields[0].Name != "CPU" || embed.Fields[0].Value != "95.0%\n🟥 ALERT" || embed.Fields[3].Name != "Disk /data" {
+		t.Fatalf("unexpected fields: %+v", embed.Fields)
+	}
+}
+
+func TestDiscordAlertColors(t *testing.T) {
+	msg := alertMessage(alerts.SeverityWarning)
+	if got := discordPayload(msg).Embeds[0]; got.Color != DiscordColorWarning || got.Description == "" || got.Image.URL != "attachment://alert.png" {
+		t.Fatalf("unexpected warning embed: %+v", got)
+	}
+	msg = alertMessage(alerts.SeverityWarning, alerts.SeverityCritical)
+	if got := discordPayload(msg).Embeds[0].Color; got != DiscordColorCritical {
+		t.Fatalf("expected critical color, got %#x", got)
+	}
+	msg.Type = TypeResolved
+	if got := discordPayload(msg).Embeds[0].Color; got != DiscordColorResolved {
+		t.Fatalf("expected resolved color, got %#x", got)
+	}
+}
+
+func TestDiscordRetriesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	var retriedAt time.Time
+	start := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.1,"global":false}`))
+			return
+		}
+		retriedAt = time.Now()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord := NewDiscord(DiscordConfig{URL: server.URL, Retries: 1}, server.Client())
+	if err := discord.Notify(context.Background(), alertMessage(alerts.SeverityCritical)); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if calls.Load() != 2 || retriedAt.Sub(start) < 100*time.Millisecond {
+		t.Fatalf("expected one retry after retry_after, got %d calls after %s", calls.Load(), retriedAt.Sub(start))
+	}
+}
+
+func TestDiscordWaitsForRateLimitBucket(t *testing.T) {
+	var last time.Time
+	var gap time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !last.IsZero() {
+			gap = time.Since(last)
+		}
+		last = time.Now()
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "0.1")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord := NewDiscord(DiscordConfig{URL: server.URL}, server.Client())
+	for range 2 {
+		if err := discord.Notify(context.Background(), alertMessage(alerts.SeverityWarning)); err != nil {
+			t.Fatalf("expected send to succeed, got %v", err)
+		}
+	}
+	if gap < 90*time.Millisecond {
+		t.Fatalf("expected second request to wait for the bucket reset, got %s", gap)
+	}
+}
+
+func TestDiscordDropsMessagePastDeadline(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":30,"global":false}`))
+	}))
+	defer server.Close()
+
+	discord := NewDiscord(DiscordConfig{URL: server.URL, Retries: 2}, server.Client())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := discord.Notify(ctx, alertMessage(alerts.SeverityCritical)); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if calls.Load() != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("expected to give up without sleeping past the deadline, got %d calls after %s", calls.Load(), time.Since(start))
+	}
+}
+
+func TestDiscordDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"message":"Invalid Webhook Token"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := NewDiscord(DiscordConfig{URL: server.URL, Retries: 3}, server.Client()).Notify(context.Background(), alertMessage(alerts.SeverityCritical))
+	if err == nil || !strings.Contains(err.Error(), "401") || calls.Load() != 1 {
+		t.Fatalf("expected single failed request, got %v after %d calls", err, calls.Load())
+	}
+	if NewDiscord(DiscordConfig{}, nil) != nil {
+		t.Fatalf("expected nil discord notifier without url")
+	}
+}
@@ -0,0 +1,290 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EmailSecurityNone     = "none"
+	EmailSecuritySTARTTLS = "starttls"
+	EmailSecurityTLS      = "tls"
+
+	EmailAuthPlain = "plain"
+	EmailAuthLogin = "login"
+)
+
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	Security string
+	Auth     string
+	Timeout  time.Duration
+}
+
+type Email struct {
+	cfg       EmailConfig
+	tlsConfig *tls.Config
+}
+
+func NewEmail(cfg EmailConfig) *Email {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Email{cfg: cfg, tlsConfig: &tls.Config{ServerName: cfg.Host}}
+}
+
+func (e *Email) Name() string {
+	return "email"
+}
+
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	data, err := e.compose(msg, time.Now())
+	if err != nil {
+		return err
+	}
+	return e.send(ctx, data)
+}
+
+func (e *Email) compose(msg Message, now time.Time) ([]byte, error) {
+	image, err := msg.PNG()
+	if err != nil {
+		return nil, err
+	}
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+
+	var body bytes.Buffer
+	alternative := multipart.NewWriter(&body)
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", strings.Join(e.cfg.To, ", "))
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Title()))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(from.Address))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", "multipart/alternative; boundary="+alternative.Boundary())
+	buf.WriteString("\r\n")
+
+	if err := writeTextPart(alternative, "text/plain; charset=utf-8", msg.Text()); err != nil {
+		return nil, err
+	}
+	var relatedBody bytes.Buffer
+	related := multipart.NewWriter(&relatedBody)
+	if err := writeTextPart(related, "text/html; charset=utf-8", emailHTML(msg)); err != nil {
+		return nil, err
+	}
+	imageHeader := textproto.MIMEHeader{}
+	imageHeader.Set("Content-Type", "image/png")
+	imageHeader.Set("Content-Transfer-Encoding", "base64")
+	imageHeader.Set("Content-ID", "<"+msg.Filename()+">")
+	imageHeader.Set("Content-Disposition", "inline; filename=\""+msg.Filename()+"\"")
+	part, err := related.CreatePart(imageHeader)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64(part, image); err != nil {
+		return nil, err
+	}
+	if err := related.Close(); err != nil {
+		return nil, err
+	}
+	relatedHeader := textproto.MIMEHeader{}
+	relatedHeader.Set("Content-Type", "multipart/related; boundary="+related.Boundary())
+	part, err = alternative.CreatePart(relatedHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(relatedBody.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func emailHTML(msg Message) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><body>\n")
+	b.WriteString(`<div style="white-space: pre-wrap; font-family: sans-serif">`)
+	if msg.Type == TypeReport {
+		b.WriteString(msg.HTML())
+	} else {
+		b.WriteString(msg.HeaderHTML() + "\n<pre>" + html.EscapeString(msg.Text()) + "</pre>")
+	}
+	b.WriteString("</div>\n")
+	_, _ = fmt.Fprintf(&b, `<p><img src="cid:%s" alt="%s"></p>`, msg.Filename(), html.EscapeString(msg.Title()))
+	b.WriteString("\n</body></html>\n")
+	return b.String()
+}
+
+func writeHeader(buf *bytes.Buffer, key string, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+func writeTextPart(writer *multipart.Writer, contentType string, text string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	return writeBase64(part, []byte(text))
+}
+
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if _, host, ok := strings.Cut(from, "@"); ok && host != "" {
+		domain = host
+	}
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+func (e *Email) send(ctx context.Context, data []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	dialer := &net.Dialer{Timeout: e.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(e.cfg.Timeout))
+	if e.cfg.Security == EmailSecurityTLS {
+		tlsConn := tls.Client(conn, e.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return err
+		}
+		conn = tlsConn
+	}
+	client, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.cfg.Security == EmailSecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(e.tlsConfig); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		auth := smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+		if e.cfg.Auth == EmailAuthLogin {
+			auth = &loginAuth{username: e.cfg.Username, password: e.cfg.Password, host: e.cfg.Host}
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected login challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,274 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	done     chan struct{}
+	auth     []string
+	from     string
+	to       []string
+	data     []byte
+	usedTLS  bool
+}
+
+func startFakeSMTP(t *testing.T, tlsConfig *tls.Config) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSMTP{listener: listener, tls: tlsConfig, done: make(chan struct{})}
+	go server.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	return server
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = io.WriteString(conn, line+"\r\n")
+	}
+	readLine := func() string {
+		line, _ := reader.ReadString('\n')
+		return strings.TrimRight(line, "\r\n")
+	}
+	reply("220 localhost ESMTP fake")
+	for {
+		line := readLine()
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if s.tls != nil && !s.usedTLS {
+				reply("250-localhost")
+				reply("250-STARTTLS")
+			} else {
+				reply("250-localhost")
+			}
+			reply("250 AUTH PLAIN LOGIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			s.usedTLS = true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			switch strings.ToUpper(mechanism) {
+			case "PLAIN":
+				decoded, _ := base64.StdEncoding.DecodeString(initial)
+				s.auth = append(s.auth, "PLAIN", string(decoded))
+			case "LOGIN":
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				user, _ := base64.StdEncoding.DecodeString(readLine())
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				pass, _ := base64.StdEncoding.DecodeString(readLine())
+				s.auth = append(s.auth, "LOGIN", string(user)+":"+string(pass))
+			}
+			reply("235 authenticated")
+		case "MAIL":
+			s.from = arg
+			reply("250 ok")
+		case "RCPT":
+			s.to = append(s.to, arg)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data bytes.Buffer
+			for {
+				line := readLine()
+				if line == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, ".") + "\r\n")
+			}
+			s.data = data.Bytes()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		case "":
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("fake smtp server did not finish")
+	}
+}
+
+func reportMessage() Message {
+	return Message{
+		Type:    TypeReport,
+		Metrics: monitor.Metrics{Hostname: "web-1", CPUPercent: 12.5, MemPercent: 40, Disks: []monitor.DiskUsage{{Mountpoint: "/", UsedPercent: 50}}},
+		Levels:  testStatusLevels(),
+	}
+}
+
+func TestEmailSendsMultipartReport(t *testing.T) {
+	server := startFakeSMTP(t, nil)
+	email := NewEmail(EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "monitor",
+		Password: "secret",
+		From:     "Monitor <monitor@example.com>",
+		To:       []string{"ops@example.com", "Admin <admin@example.com>"},
+		Security: EmailSecurityNone,
+	})
+	if err := email.Notify(context.Background(), reportMessage()); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	server.wait(t)
+
+	if len(server.auth) != 2 || server.auth[0] != "PLAIN" || server.auth[1] != "\x00monitor\x00secret" {
+		t.Fatalf("unexpected auth: %q", server.auth)
+	}
+	if server.from != "FROM:<monitor@example.com>" || len(server.to) != 2 || server.to[1] != "TO:<admin@example.com>" {
+		t.Fatalf("unexpected envelope: %q %q", server.from, server.to)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != "Simple System Monitor - web-1" {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	parts := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0].contentType, "text/plain") || !strings.HasPrefix(parts[1].contentType, "multipart/related") {
+		t.Fatalf("unexpected alternative parts: %#v", parts)
+	}
+	if !strings.Contains(string(parts[0].body), "12.5%  OK") {
+		t.Fatalf("expected text report in plain part, got:\n%s", parts[0].body)
+	}
+	related := readParts(t, parts[1].contentType, bytes.NewReader(parts[1].body))
+	if len(related) != 2 || !strings.HasPrefix(related[0].contentType, "text/html") || related[1].contentType != "image/png" {
+		t.Fatalf("unexpected related parts: %#v", related)
+	}
+	htmlBody := string(related[0].body)
+	if !strings.Contains(htmlBody, "<b>Simple System Monitor</b>") || !strings.Contains(htmlBody, `src="cid:metrics.png"`) {
+		t.Fatalf("unexpected html part:\n%s", htmlBody)
+	}
+	if related[1].contentID != "<metrics.png>" || !bytes.HasPrefix(related[1].body, []byte("\x89PNG")) {
+		t.Fatalf("unexpected image part: %q %q", related[1].contentID, related[1].body[:8])
+	}
+}
+
+func TestEmailStartTLSWithLoginAuth(t *testing.T) {
+	tlsServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsServer.Certificate())
+
+	server := startFakeSMTP(t, &tls.Config{Certificates: tlsServer.TLS.Certificates})
+	email := NewEmail(EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "monitor",
+		Password: "secret",
+		From:     "monitor@example.com",
+		To:       []string{"ops@example.com"},
+		Security: EmailSecuritySTARTTLS,
+		Auth:     EmailAuthLogin,
+	})
+	email.tlsConfig = &tls.Config{ServerName: "127.0.0.1", RootCAs: pool}
+	if err := email.Notify(context.Background(), alertMessage(alerts.SeverityCritical)); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	server.wait(t)
+	if !server.usedTLS || len(server.auth) != 2 || server.auth[0] != "LOGIN" || server.auth[1] != "monitor:secret" {
+		t.Fatalf("expected STARTTLS with LOGIN auth, got tls=%v auth=%q", server.usedTLS, server.auth)
+	}
+}
+
+func TestEmailRequiresStartTLS(t *testing.T) {
+	server := startFakeSMTP(t, nil)
+	email := NewEmail(EmailConfig{Host: "127.0.0.1", Port: server.port(), From: "monitor@example.com", To: []string{"ops@example.com"}, Security: EmailSecuritySTARTTLS})
+	if err := email.Notify(context.Background(), reportMessage()); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected STARTTLS error, got %v", err)
+	}
+	if NewEmail(EmailConfig{Host: "smtp.example.com"}) != nil {
+		t.Fatalf("expected nil email notifier without sender and recipients")
+	}
+}
+
+type mailPart struct {
+	contentType string
+	contentID   string
+	body        []byte
+}
+
+func readParts(t *testing.T, contentType string, body io.Reader) []mailPart {
+	t.Helper()
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("invalid content type %q: %v", contentType, err)
+	}
+	reader := multipart.NewReader(body, params["boundary"])
+	parts := []mailPart{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("invalid multipart body: %v", err)
+		}
+		data, _ := io.ReadAll(part)
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			data, err = base64.StdEncoding.DecodeString(strings.ReplaceAll(string(data), "\r\n", ""))
+			if err != nil {
+				t.Fatalf("invalid base64 part: %v", err)
+			}
+		}
+		parts = append(parts, mailPart{contentType: part.Header.Get("Content-Type"), contentID: part.Header.Get("Content-ID"), body: data})
+	}
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+	"github.com/zergo0/simple-system-monitor/internal/render"
+)
+
+type Type string
+
+const (
+	TypeReport   Type = "report"
+	TypeAlert    Type = "alert"
+	TypeResolved Type = "resolved"
+)
+
+type Message struct {
+	Type       Type
+	Hostname   string
+	SystemName string
+	Metrics    monitor.Metrics
+	Levels     monitor.StatusLevels
+	Events     []alerts.Event
+	Processes  *monitor.TopProcesses
+	At         time.Time
+}
+
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+func (m Message) Severity() alerts.Severity {
+	return alerts.MaxSeverity(m.Events)
+}
+
+func (m Message) Title() string {
+	if m.Type == TypeReport {
+		return monitor.FormatMetricsHeaderText(m.Metrics)
+	}
+	title := "🚨 ALERT"
+	switch {
+	case m.Type == TypeResolved:
+		title = "✅ RESOLVED"
+	case m.Severity() == alerts.SeverityWarning:
+		title = "⚠️ WARNING"
+	}
+	if host := monitor.CleanText(m.Metrics.Hostname); host != "" {
+		title = fmt.Sprintf("%s - %s", title, host)
+	}
+	return title
+}
+
+func (m Message) HeaderHTML() string {
+	return "<b>" + html.EscapeString(m.Title()) + "</b>"
+}
+
+func (m Message) Text() string {
+	text := ""
+	if m.Type == TypeReport {
+		text = monitor.FormatMetricsText(m.Metrics, m.Levels)
+	} else {
+		lines := make([]string, 0, len(m.Events))
+		for _, event := range m.Events {
+			lines = append(lines, monitor.CleanText(event.Text()))
+		}
+		text = strings.Join(lines, "\n")
+	}
+	if m.Processes != nil {
+		if processes := monitor.FormatProcessesText(*m.Processes); processes != "" {
+			text += "\n" + processes
+		}
+	}
+	return text
+}
+
+func (m Message) HTML() string {
+	if m.Type != TypeReport {
+		return m.HeaderHTML() + "\n<pre>" + html.EscapeString(m.Text()) + "</pre>"
+	}
+	body := monitor.FormatMetricsHTML(m.Metrics, m.Levels)
+	if m.Processes != nil {
+		if processes := monitor.FormatProcessesText(*m.Processes); processes != "" {
+			body += "\n<pre>" + html.EscapeString(strings.TrimPrefix(processes, "\n")) + "</pre>"
+		}
+	}
+	return body
+}
+
+func (m Message) PNG() ([]byte, error) {
+	return render.TextPNG(m.Text())
+}
+
+func (m Message) Filename() string {
+	switch m.Type {
+	case TypeReport:
+		return "metrics.png"
+	case TypeResolved:
+		return "resolved.png"
+	default:
+		return "alert.png"
+	}
+}
+
+type Route struct {
+	Notifier    Notifier
+	MinSeverity alerts.Severity
+	Reports     bool
+}
+
+func (r Route) filter(msg Message) (Message, bool) {
+	if msg.Type == TypeReport {
+		return msg, r.Reports
+	}
+	if r.MinSeverity != alerts.SeverityCritical {
+		return msg, len(msg.Events) > 0
+	}
+	events := make([]alerts.Event, 0, len(msg.Events))
+	for _, event := range msg.Events {
+		if event.HighestSeverity() == alerts.SeverityCritical {
+			events = append(events, event)
+		}
+	}
+	msg.Events = events
+	return msg, len(events) > 0
+}
+
+type Dispatcher struct {
+	logger  *zap.Logger
+	routes  []Route
+	timeout time.Duration
+}
+
+func NewDispatcher(logger *zap.Logger, routes []Route, timeout time.Duration) *Dispatcher {
+	return &Dispatcher{logger: logger, routes: routes, timeout: timeout}
+}
+
+func (d *Dispatcher) Enabled() bool {
+	return d != nil && len(d.routes) > 0
+}
+
+func (d *Dispatcher) Reports() bool {
+	if d == nil {
+		return false
+	}
+	for _, route := range d.routes {
+		if route.Reports {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) Send(ctx context.Context, msg Message) {
+	if d == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, route := range d.routes {
+		routed, ok := route.filter(msg)
+		if !ok {
+			continue
+		}
+		wg.Go(func() {
+			d.notify(ctx, route.Notifier, routed)
+		})
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) notify(ctx context.Context, notifier Notifier, msg Message) {
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+	if err := notifier.Notify(ctx, msg); err != nil {
+		d.logger.Warn("notification failed", zap.String("notifier", notifier.Name()), zap.String("type", string(msg.Type)), zap.Error(err))
+	}
+}
@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+type fakeNotifier struct {
+	name     string
+	err      error
+	messages []Message
+}
+
+func (f *fakeNotifier) Name() string {
+	return f.name
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, msg Message) error {
+	f.messages = append(f.messages, msg)
+	return f.err
+}
+
+type blockingNotifier struct{}
+
+func (blockingNotifier) Name() string {
+	return "blocking"
+}
+
+func (blockingNotifier) Notify(ctx context.Context, _ Message) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func alertMessage(severities ...alerts.Severity) Message {
+	msg := Message{Type: TypeAlert, Metrics: monitor.Metrics{Hostname: "web-1"}}
+	for _, severity := range severities {
+		msg.Events = append(msg.Events, alerts.Event{Kind: alerts.KindCPU, Severity: severity, Value: 95, Threshold: 90, Transition: alerts.TransitionFiring})
+	}
+	return msg
+}
+
+func testStatusLevels() monitor.StatusLevels {
+	levels := monitor.Levels{Warn: 75, Crit: 90}
+	return monitor.StatusLevels{CPU: levels, Mem: levels, Disk: levels, Inodes: monitor.Levels{Crit: 90}}
+}
+
+func TestDispatcherRoutesBySeverity(t *testing.T) {
+	all := &fakeNotifier{name: "all"}
+	critical := &fakeNotifier{name: "critical"}
+	dispatcher := NewDispatcher(zap.NewNop(), []Route{
+		{Notifier: all, MinSeverity: alerts.SeverityWarning, Reports: true},
+		{Notifier: critical, MinSeverity: alerts.SeverityCritical},
+	}, 0)
+
+	dispatcher.Send(context.Background(), alertMessage(alerts.SeverityWarning))
+	if len(all.messages) != 1 || len(critical.messages) != 0 {
+		t.Fatalf("expected warning only on the warning route, got %d/%d", len(all.messages), len(critical.messages))
+	}
+
+	dispatcher.Send(context.Background(), alertMessage(alerts.SeverityWarning, alerts.SeverityCritical))
+	if len(all.messages) != 2 || len(all.messages[1].Events) != 2 {
+		t.Fatalf("expected both events on the warning route, got %#v", all.messages)
+	}
+	if len(critical.messages) != 1 || len(critical.messages[0].Events) != 1 || critical.messages[0].Events[0].Severity != alerts.SeverityCritical {
+		t.Fatalf("expected only the critical event on the critical route, got %#v", critical.messages)
+	}
+
+	dispatcher.Send(context.Background(), Message{Type: TypeReport})
+	if len(all.messages) != 3 || len(critical.messages) != 1 {
+		t.Fatalf("expected report only on the reports route, got %d/%d", len(all.messages), len(critical.messages))
+	}
+	if !dispatcher.Enabled() || !dispatcher.Reports() {
+		t.Fatalf("expected dispatcher with routes to be enabled")
+	}
+}
+
+func TestDispatcherCriticalRouteSeesFollowUps(t *testing.T) {
+	critical := &fakeNotifier{name: "critical"}
+	dispatcher := NewDispatcher(zap.NewNop(), []Route{{Notifier: critical, MinSeverity: alerts.SeverityCritical}}, 0)
+	events := []alerts.Event{
+		{Kind: alerts.KindCPU, Severity: alerts.SeverityWarning, Highest: alerts.SeverityWarning, Transition: alerts.TransitionFiring},
+		{Kind: alerts.KindCPU, Severity: alerts.SeverityCritical, Previous: alerts.SeverityWarning, Highest: alerts.SeverityCritical, Transition: alerts.TransitionEscalated},
+		{Kind: alerts.KindCPU, Severity: alerts.SeverityWarning, Previous: alerts.SeverityCritical, Highest: alerts.SeverityCritical, Transition: alerts.TransitionDeescalated},
+		{Kind: alerts.KindCPU, Severity: alerts.SeverityWarning, Highest: alerts.SeverityCritical, Transition: alerts.TransitionResolved},
+	}
+	for _, event := range events {
+		msg := Message{Type: TypeAlert, Events: []alerts.Event{event}}
+		if event.Resolved() {
+			msg.Type = TypeResolved
+		}
+		dispatcher.Send(context.Background(), msg)
+	}
+	if len(critical.messages) != 3 {
+		t.Fatalf("expected escalation, de-escalation and resolve on the critical route, got %#v", critical.messages)
+	}
+	if critical.messages[2].Type != TypeResolved {
+		t.Fatalf("expected resolved message last, got %#v", critical.messages[2])
+	}
+}
+
+func TestDispatcherContinuesAfterError(t *testing.T) {
+	failing := &fakeNotifier{name: "failing", err: errors.New("boom")}
+	ok := &fakeNotifier{name: "ok"}
+	dispatcher := NewDispatcher(zap.NewNop(), []Route{{Notifier: failing}, {Notifier: ok}}, 0)
+	dispatcher.Send(context.Background(), alertMessage(alerts.SeverityCritical))
+	if len(failing.messages) != 1 || len(ok.messages) != 1 {
+		t.Fatalf("expected every notifier to be called, got %d/%d", len(failing.messages), len(ok.messages))
+	}
+}
+
+func TestDispatcherTimesOutSlowNotifier(t *testing.T) {
+	ok := &fakeNotifier{name: "ok"}
+	dispatcher := NewDispatcher(zap.NewNop(), []Route{{Notifier: blockingNotifier{}}, {Notifier: ok}}, 50*time.Millisecond)
+	start := time.Now()
+	dispatcher.Send(context.Background(), alertMessage(alerts.SeverityCritical))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected send to stop at the notifier timeout, took %s", elapsed)
+	}
+	if len(ok.messages) != 1 {
+		t.Fatalf("expected the other notifier to be called, got %d", len(ok.messages))
+	}
+}
+
+func TestNilDispatcher(t *testing.T) {
+	var dispatcher *Dispatcher
+	dispatcher.Send(context.Background(), alertMessage(alerts.SeverityCritical))
+	if dispatcher.Enabled() || dispatcher.Reports() {
+		t.Fatalf("expected nil dispatcher to be disabled")
+	}
+}
+
+func TestMessageTitle(t *testing.T) {
+	cases := []struct {
+		msg  Message
+		want string
+	}{
+		{alertMessage(alerts.SeverityCritical), "🚨 ALERT - web-1"},
+		{alertMessage(alerts.SeverityWarning), "⚠️ WARNING - web-1"},
+		{Message{Type: TypeResolved, Metrics: monitor.Metrics{Hostname: "web-1"}}, "✅ RESOLVED - web-1"},
+		{Message{Type: TypeReport, Metrics: monitor.Metrics{Hostname: "web-1"}}, "Simple System Monitor - web-1"},
+	}
+	for _, tc := range cases {
+		if got := tc.msg.Title(); got != tc.want {
+			t.Fatalf("Title() = %q, want %q", got, tc.want)
+		}
+	}
+	if got := alertMessage(alerts.SeverityCritical).HeaderHTML(); got != "<b>🚨 ALERT - web-1</b>" {
+		t.Fatalf("unexpected header: %q", got)
+	}
+}
+
+func TestMessageTextIncludesProcesses(t *testing.T) {
+	msg := alertMessage(alerts.SeverityCritical)
+	msg.Processes = &monitor.TopProcesses{ByCPU: []monitor.ProcessInfo{{PID: 42, Name: "stress", CPUPercent: 99}}}
+	text := msg.Text()
+	if !strings.HasPrefix(text, "CRIT CPU 95.0% >= 90.0%") || !strings.Contains(text, "\n\nTop CPU\n") {
+		t.Fatalf("unexpected alert text:\n%s", text)
+	}
+	if html := msg.HTML(); !strings.HasPrefix(html, "<b>🚨 ALERT - web-1</b>\n<pre>CRIT CPU") {
+		t.Fatalf("unexpected alert html:\n%s", html)
+	}
+	if msg.Filename() != "alert.png" || (Message{Type: TypeReport}).Filename() != "metrics.png" {
+		t.Fatalf("unexpected filenames")
+	}
+}
@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+const (
+	SlackColorCritical = "#d32f2f"
+	SlackColorWarning  = "#f9a825"
+	SlackColorResolved = "#2e7d32"
+	SlackColorReport   = "#1e88e5"
+)
+
+type SlackConfig struct {
+	URL      string
+	Channel  string
+	Username string
+	Timeout  time.Duration
+}
+
+type Slack struct {
+	cfg    SlackConfig
+	client *http.Client
+}
+
+type SlackPayload struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Blocks      []SlackBlock      `json:"blocks,omitempty"`
+	Attachments []SlackAttachment `json:"attachments,omitempty"`
+}
+
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackAttachment struct {
+	Color    string   `json:"color"`
+	Fallback string   `json:"fallback"`
+	Text     string   `json:"text"`
+	MrkdwnIn []string `json:"mrkdwn_in"`
+}
+
+func NewSlack(cfg SlackConfig, httpClient *http.Client) *Slack {
+	if cfg.URL == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Slack{cfg: cfg, client: httpClient}
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+func (s *Slack) Notify(ctx context.Context, msg Message) error {
+	payload := slackPayload(msg)
+	payload.Channel = s.cfg.Channel
+	payload.Username = s.cfg.Username
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "simple-system-monitor")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+func slackPayload(msg Message) SlackPayload {
+	title := msg.Title()
+	blocks := []SlackBlock{{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: "*" + slackEscape(title) + "*"}}}
+	if line := slackContext(msg); line != "" {
+		blocks = append(blocks, SlackBlock{Type: "context", Elements: []SlackText{{Type: "mrkdwn", Text: slackEscape(line)}}})
+	}
+
+	attachments := []SlackAttachment{}
+	if msg.Type == TypeReport {
+		text := monitor.FormatMetricsText(msg.Metrics, msg.Levels)
+		attachments = append(attachments, slackAttachment(SlackColorReport, text, slackCode(text)))
+	} else {
+		for _, event := range msg.Events {
+			text := monitor.CleanText(event.Text())
+			attachments = append(attachments, slackAttachment(slackColor(msg.Type, event.Severity), text, slackEscape(text)))
+		}
+	}
+	if msg.Processes != nil {
+		if processes := strings.TrimPrefix(monitor.FormatProcessesText(*msg.Processes), "\n"); processes != "" {
+			attachments = append(attachments, slackAttachment("", processes, slackCode(processes)))
+		}
+	}
+	return SlackPayload{Text: title, Blocks: blocks, Attachments: attachments}
+}
+
+func slackContext(msg Message) string {
+	parts := []string{}
+	if msg.SystemName != "" {
+		parts = append(parts, monitor.CleanText(msg.SystemName))
+	}
+	if !msg.At.IsZero() {
+		parts = append(parts, msg.At.Format("2006-01-02 15:04 MST"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func slackAttachment(color string, fallback string, text string) SlackAttachment {
+	return SlackAttachment{Color: color, Fallback: fallback, Text: text, MrkdwnIn: []string{"text"}}
+}
+
+func slackColor(msgType Type, severity alerts.Severity) string {
+	switch {
+	case msgType == TypeResolved:
+		return SlackColorResolved
+	case severity == alerts.SeverityCritical:
+		return SlackColorCritical
+	default:
+		return SlackColorWarning
+	}
+}
+
+func slackCode(text string) string {
+	return "```\n" + slackEscape(strings.ReplaceAll(text, "```", "'''")) + "\n```"
+}
+
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+func TestSlackAlertColorsBySeverity(t *testing.T) {
+	var payload SlackPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	slack := NewSlack(SlackConfig{URL: server.URL, Channel: "#ops", Username: "monitor"}, server.Client())
+	msg := alertMessage(alerts.SeverityWarning, alerts.SeverityCritical)
+	msg.SystemName = "Web"
+	msg.At = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := slack.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	if payload.Text != "🚨 ALERT - web-1" || payload.Channel != "#ops" || payload.Username != "monitor" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if len(payload.Blocks) != 2 || payload.Blocks[0].Text.Text != "*🚨 ALERT - web-1*" || payload.Blocks[1].Elements[0].Text != "Web · 2024-05-01 12:00 UTC" {
+		t.Fatalf("unexpected blocks: %+v", payload.Blocks)
+	}
+	if len(payload.Attachments) != 2 || payload.Attachments[0].Color != SlackColorWarning || payload.Attachments[1].Color != SlackColorCritical {
+		t.Fatalf("unexpected attachments: %+v", payload.Attachments)
+	}
+
+	msg.Type = TypeResolved
+	payload = slackPayload(msg)
+	for _, attachment := range payload.Attachments {
+		if attachment.Color != SlackColorResolved {
+			t.Fatalf("expected resolved events in green, got %+v", payload.Attachments)
+		}
+	}
+}
+
+func TestSlackReportUsesCodeBlockTable(t *testing.T) {
+	msg := Message{
+		Type:      TypeReport,
+		Metrics:   monitor.Metrics{Hostname: "web-1", CPUPercent: 12.5, Disks: []monitor.DiskUsage{{Mountpoint: "/a<b>", UsedPercent: 50}}},
+		Levels:    testStatusLevels(),
+		Processes: &monitor.TopProcesses{ByCPU: []monitor.ProcessInfo{{PID: 42, Name: "nginx", CPUPercent: 80}}},
+	}
+	payload := slackPayload(msg)
+	if payload.Text != "Simple System Monitor - web-1" || len(payload.Blocks) != 1 || len(payload.Attachments) != 2 {
+		t.Fatalf("unexpected report payload: %+v", payload)
+	}
+	report := payload.Attachments[0]
+	if report.Color != SlackColorReport || !strings.HasPrefix(report.Text, "```\n") || !strings.HasSuffix(report.Text, "\n```") {
+		t.Fatalf("expected code block report, got %+v", report)
+	}
+	if !strings.Contains(report.Text, "12.5%  OK") || !strings.Contains(report.Text, "/a&lt;b&gt;") || !strings.Contains(report.Fallback, "/a<b>") {
+		t.Fatalf("unexpected report text:\n%s", report.Text)
+	}
+	if !strings.Contains(payload.Attachments[1].Text, "nginx") {
+		t.Fatalf("expected top processes attachment, got %+v", payload.Attachments[1])
+	}
+}
+
+func TestSlackReportsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewSlack(SlackConfig{URL: server.URL}, server.Client()).Notify(context.Background(), alertMessage(alerts.SeverityCritical))
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	if NewSlack(SlackConfig{}, nil) != nil {
+		t.Fatalf("expected nil slack notifier without url")
+	}
+}
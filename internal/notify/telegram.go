@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/zergo0/simple-system-monitor/internal/telegram"
+)
+
+type Telegram struct {
+	client *telegram.Client
+}
+
+func NewTelegram(client *telegram.Client) *Telegram {
+	if client == nil {
+		return nil
+	}
+	return &Telegram{client: client}
+}
+
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+func (t *Telegram) Notify(ctx context.Context, msg Message) error {
+	image, err := msg.PNG()
+	if err != nil {
+		return err
+	}
+	return t.client.SendPNGWithCaption(ctx, msg.Filename(), image, msg.HeaderHTML(), "HTML")
+}
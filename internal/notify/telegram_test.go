@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/telegram"
+)
+
+func TestTelegramSendsImageWithCaption(t *testing.T) {
+	var caption, parseMode, filename string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendPhoto" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		caption = r.FormValue("caption")
+		parseMode = r.FormValue("parse_mode")
+		if _, header, err := r.FormFile("photo"); err == nil {
+			filename = header.Filename
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewTelegram(telegram.NewWithBaseURL("token", "chat", server.URL, server.Client()))
+	if err := notifier.Notify(context.Background(), alertMessage(alerts.SeverityCritical)); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	if caption != "<b>🚨 ALERT - web-1</b>" || parseMode != "HTML" || filename != "alert.png" {
+		t.Fatalf("unexpected request: caption=%q parse_mode=%q filename=%q", caption, parseMode, filename)
+	}
+	if NewTelegram(nil) != nil {
+		t.Fatalf("expected nil notifier without client")
+	}
+}
@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+const SignatureHeader = "X-Signature-256"
+
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	Secret  string
+	Timeout time.Duration
+	Retries int
+}
+
+type Webhook struct {
+	cfg     WebhookConfig
+	client  *http.Client
+	backoff time.Duration
+	maxWait time.Duration
+}
+
+type WebhookPayload struct {
+	Type         Type                  `json:"type"`
+	Hostname     string                `json:"hostname"`
+	SystemName   string                `json:"system_name,omitempty"`
+	Title        string                `json:"title"`
+	Severity     alerts.Severity       `json:"severity,omitempty"`
+	At           time.Time             `json:"at"`
+	Metrics      monitor.Metrics       `json:"metrics"`
+	Events       []alerts.Event        `json:"events"`
+	TopProcesses *monitor.TopProcesses `json:"top_processes,omitempty"`
+}
+
+func NewWebhook(cfg WebhookConfig, httpClient *http.Client) *Webhook {
+	if cfg.URL == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Webhook{cfg: cfg, client: httpClient, backoff: time.Second, maxWait: time.Minute}
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(webhookPayload(msg))
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	wait := time.Duration(0)
+	for attempt := 0; attempt <= w.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			if wait <= 0 {
+				wait = w.backoff * time.Duration(1<<(attempt-1))
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return lastErr
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		retry, retryAfter, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+		wait = min(retryAfter, w.maxWait)
+	}
+	return lastErr
+}
+
+func webhookPayload(msg Message) WebhookPayload {
+	events := msg.Events
+	if events == nil {
+		events = []alerts.Event{}
+	}
+	return WebhookPayload{
+		Type:         msg.Type,
+		Hostname:     msg.Hostname,
+		SystemName:   msg.SystemName,
+		Title:        msg.Title(),
+		Severity:     msg.Severity(),
+		At:           msg.At.UTC(),
+		Metrics:      msg.Metrics,
+		Events:       events,
+		TopProcesses: msg.Processes,
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "simple-system-monitor")
+	for key, value := range w.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	if w.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.cfg.Secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return false, 0, err
+		}
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		wait, _ := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return retry, wait, fmt.Errorf("webhook status %d", resp.StatusCode)
+	}
+	return false, 0, nil
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if wait, ok := parseSeconds(value); ok {
+		return wait, true
+	}
+	at, err := http.ParseTime(strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+func parseSeconds(value string) (time.Duration, bool) {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+func TestWebhookPostsSignedPayload(t *testing.T) {
+	var (
+		body      []byte
+		signature string
+		token     string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		token = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer abc"}, Secret: "s3cret"}, server.Client())
+	msg := alertMessage(alerts.SeverityCritical)
+	msg.Hostname = "web-1"
+	msg.SystemName = "Web"
+	msg.At = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := webhook.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	if token != "Bearer abc" {
+		t.Fatalf("expected custom header, got %q", token)
+	}
+	if signature != Sign("s3cret", body) || len(signature) != len("sha256=")+64 {
+		t.Fatalf("unexpected signature %q", signature)
+	}
+
+	var payload struct {
+		Type       string          `json:"type"`
+		Hostname   string          `json:"hostname"`
+		SystemName string          `json:"system_name"`
+		Title      string          `json:"title"`
+		Severity   string          `json:"severity"`
+		At         time.Time       `json:"at"`
+		Metrics    monitor.Metrics `json:"metrics"`
+		Events     []alerts.Event  `json:"events"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v\n%s", err, body)
+	}
+	if payload.Type != "alert" || payload.Hostname != "web-1" || payload.SystemName != "Web" || payload.Severity != "critical" || payload.Title != "🚨 ALERT - web-1" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if !payload.At.Equal(msg.At) || payload.Metrics.Hostname != "web-1" || len(payload.Events) != 1 || payload.Events[0].Kind != alerts.KindCPU {
+		t.Fatalf("unexpected payload content: %+v", payload)
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Retries: 2}, server.Client())
+	webhook.backoff = time.Millisecond
+	if err := webhook.Notify(context.Background(), Message{Type: TypeReport}); err != nil {
+		t.Fatalf("expected send to succeed after retries, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestWebhookHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var retriedAfter time.Duration
+	start := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0.1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		retriedAfter = time.Since(start)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Retries: 1}, server.Client())
+	webhook.backoff = time.Millisecond
+	if err := webhook.Notify(context.Background(), Message{Type: TypeReport}); err != nil {
+		t.Fatalf("expected send to succeed after retry, got %v", err)
+	}
+	if calls.Load() != 2 || retriedAfter < 100*time.Millisecond {
+		t.Fatalf("expected retry after Retry-After delay, got %d calls after %s", calls.Load(), retriedAfter)
+	}
+}
+
+func TestWebhookRetryAfterPastDeadline(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Retries: 2}, server.Client())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := webhook.Notify(ctx, Message{Type: TypeReport}); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected 429 error, got %v", err)
+	}
+	if calls.Load() != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("expected to give up without waiting past the deadline, got %d calls after %s", calls.Load(), time.Since(start))
+	}
+}
+
+func TestWebhookCapsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Retries: 1}, server.Client())
+	webhook.maxWait = 10 * time.Millisecond
+	start := time.Now()
+	if err := webhook.Notify(context.Background(), Message{Type: TypeReport}); err != nil {
+		t.Fatalf("expected send to succeed after capped wait, got %v", err)
+	}
+	if calls.Load() != 2 || time.Since(start) > time.Second {
+		t.Fatalf("expected retry after the capped wait, got %d calls after %s", calls.Load(), time.Since(start))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"120":                           2 * time.Minute,
+		"1.5":                           1500 * time.Millisecond,
+		"Wed, 01 May 2024 12:00:30 GMT": 30 * time.Second,
+		"Wed, 01 May 2024 11:00:00 GMT": 0,
+	}
+	for value, want := range cases {
+		if got, ok := parseRetryAfter(value, now); !ok || got != want {
+			t.Fatalf("parseRetryAfter(%q) = %s, %v, want %s", value, got, ok, want)
+		}
+	}
+	for _, value := range []string{"", "-1", "soon"} {
+		if _, ok := parseRetryAfter(value, now); ok {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Retries: 3}, server.Client())
+	webhook.backoff = time.Millisecond
+	if err := webhook.Notify(context.Background(), Message{Type: TypeReport}); err == nil {
+		t.Fatalf("expected error for 400 response")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(WebhookConfig{URL: server.URL, Timeout: 20 * time.Millisecond}, server.Client())
+	if err := webhook.Notify(context.Background(), Message{Type: TypeReport}); err == nil {
+		t.Fatalf("expected timeout error")
+	}
+	if NewWebhook(WebhookConfig{}, nil) != nil {
+		t.Fatalf("expected nil webhook without url")
+	}
+}
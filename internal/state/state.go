@@ -0,0 +1,102 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+)
+
+const (
+	fileName       = "state.json"
+	currentVersion = 1
+)
+
+type Snapshot struct {
+	Version        int                `json:"version"`
+	SavedAt        time.Time          `json:"saved_at"`
+	LastTelegramAt time.Time          `json:"last_telegram_at"`
+	Alerts         *alerts.AlertState `json:"alerts"`
+}
+
+type Store struct {
+	path string
+}
+
+func New(dir string) *Store {
+	if dir == "" {
+		return nil
+	}
+	return &Store{path: filepath.Join(dir, fileName)}
+}
+
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+func (s *Store) Load(now time.Time, maxAge time.Duration) (Snapshot, error) {
+	empty := Snapshot{Alerts: alerts.NewState()}
+	if s == nil {
+		return empty, nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return empty, nil
+		}
+		return empty, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return empty, fmt.Errorf("decode %s: %w", s.path, err)
+	}
+	if snapshot.Version != currentVersion {
+		return empty, fmt.Errorf("unsupported state version %d", snapshot.Version)
+	}
+	if snapshot.SavedAt.After(now) {
+		return empty, fmt.Errorf("state saved in the future (%s)", snapshot.SavedAt.Format(time.RFC3339))
+	}
+	if snapshot.LastTelegramAt.After(now) {
+		snapshot.LastTelegramAt = time.Time{}
+	}
+	if snapshot.Alerts == nil {
+		snapshot.Alerts = alerts.NewState()
+	}
+	stale := maxAge > 0 && now.Sub(snapshot.SavedAt) > maxAge
+	snapshot.Alerts.Sanitize(now, stale)
+	return snapshot, nil
+}
+
+func (s *Store) Save(snapshot Snapshot) error {
+	if s == nil {
+		return nil
+	}
+	snapshot.Version = currentVersion
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), fileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
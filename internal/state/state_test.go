@@ -0,0 +1,124 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zergo0/simple-system-monitor/internal/alerts"
+	"github.com/zergo0/simple-system-monitor/internal/monitor"
+)
+
+func TestNewWithoutDir(t *testing.T) {
+	store := New("")
+	if store != nil {
+		t.Fatalf("expected nil store without dir")
+	}
+	snapshot, err := store.Load(time.Now(), time.Hour)
+	if err != nil || snapshot.Alerts == nil {
+		t.Fatalf("expected empty snapshot from nil store, got %#v (%v)", snapshot, err)
+	}
+	if err := store.Save(snapshot); err != nil {
+		t.Fatalf("expected save on nil store to be a no-op, got %v", err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	store := New(t.TempDir())
+	snapshot, err := store.Load(time.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("expected missing file to be ignored, got %v", err)
+	}
+	if snapshot.Alerts == nil || len(snapshot.Alerts.Metrics) != 0 {
+		t.Fatalf("expected empty alert state")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	store := New(filepath.Join(dir, "nested"))
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	alertState := alerts.NewState()
+	alerts.Check(monitorMetrics(95), cpuThresholds(), alertState, now.Add(-10*time.Minute))
+	if len(alertState.Metrics) != 1 {
+		t.Fatalf("expected alerting cpu state")
+	}
+	if err := store.Save(Snapshot{SavedAt: now, LastTelegramAt: now.Add(-time.Hour), Alerts: alertState}); err != nil {
+		t.Fatalf("expected save to succeed, got %v", err)
+	}
+
+	loaded, err := store.Load(now.Add(time.Minute), time.Hour)
+	if err != nil {
+		t.Fatalf("expected load to succeed, got %v", err)
+	}
+	if !loaded.LastTelegramAt.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("expected last telegram time restored, got %s", loaded.LastTelegramAt)
+	}
+	cpu := loaded.Alerts.Metrics["cpu"]
+	if cpu == nil || cpu.Severity != alerts.SeverityCritical {
+		t.Fatalf("expected cpu alert restored, got %#v", loaded.Alerts.Metrics)
+	}
+
+	events := alerts.Check(monitorMetrics(95), cpuThresholds(), loaded.Alerts, now.Add(2*time.Minute))
+	if len(events) != 0 {
+		t.Fatalf("expected restored alert not to fire again, got %#v", events)
+	}
+}
+
+func TestLoadRejectsFutureAndCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	store := New(dir)
+	now := time.Now()
+	if err := store.Save(Snapshot{SavedAt: now.Add(time.Hour), Alerts: alerts.NewState()}); err != nil {
+		t.Fatalf("expected save to succeed, got %v", err)
+	}
+	if _, err := store.Load(now, time.Hour); err == nil {
+		t.Fatalf("expected state saved in the future to be rejected")
+	}
+
+	if err := os.WriteFile(store.Path(), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	snapshot, err := store.Load(now, time.Hour)
+	if err == nil {
+		t.Fatalf("expected corrupt state to fail")
+	}
+	if snapshot.Alerts == nil {
+		t.Fatalf("expected usable empty state on error")
+	}
+}
+
+func TestLoadStaleDropsPendingTimers(t *testing.T) {
+	store := New(t.TempDir())
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	alertState := alerts.NewState()
+	alertState.Metrics["mem"] = &alerts.MetricState{Crit: alerts.LevelState{AboveSince: now.Add(-3 * time.Hour)}}
+	alertState.Metrics["cpu"] = &alerts.MetricState{
+		Crit:     alerts.LevelState{AboveSince: now.Add(-3 * time.Hour), Active: true},
+		Severity: alerts.SeverityCritical,
+		Since:    now.Add(-3 * time.Hour),
+	}
+	if err := store.Save(Snapshot{SavedAt: now.Add(-2 * time.Hour), Alerts: alertState}); err != nil {
+		t.Fatalf("expected save to succeed, got %v", err)
+	}
+	loaded, err := store.Load(now, time.Hour)
+	if err != nil {
+		t.Fatalf("expected load to succeed, got %v", err)
+	}
+	if _, ok := loaded.Alerts.Metrics["mem"]; ok {
+		t.Fatalf("expected stale pending timer dropped")
+	}
+	if cpu := loaded.Alerts.Metrics["cpu"]; cpu == nil || cpu.Severity != alerts.SeverityCritical {
+		t.Fatalf("expected firing alert kept so it can resolve, got %#v", loaded.Alerts.Metrics)
+	}
+}
+
+func monitorMetrics(cpu float64) monitor.Metrics {
+	return monitor.Metrics{CPUPercent: cpu}
+}
+
+func cpuThresholds() alerts.Thresholds {
+	return alerts.Thresholds{CPU: alerts.Rule{Crit: alerts.Level{Threshold: 90}}}
+}
@@ -0,0 +1,27 @@
+package units
+
+import "fmt"
+
+func Bytes(value float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f%s", value, units[i])
+	}
+	return fmt.Sprintf("%.1f%s", value, units[i])
+}
+
+func BitRate(bitsPerSec float64) string {
+	switch {
+	case bitsPerSec >= 1e9:
+		return fmt.Sprintf("%.1fGbit/s", bitsPerSec/1e9)
+	case bitsPerSec >= 1e6:
+		return fmt.Sprintf("%.1fMbit/s", bitsPerSec/1e6)
+	default:
+		return fmt.Sprintf("%.1fkbit/s", bitsPerSec/1e3)
+	}
+}
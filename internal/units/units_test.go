@@ -0,0 +1,21 @@
+package units
+
+import "testing"
+
+func TestBytes(t *testing.T) {
+	cases := map[float64]string{512: "512B", 1536: "1.5KiB", 10 << 30: "10.0GiB", 3 << 50: "3.0PiB"}
+	for value, want := range cases {
+		if got := Bytes(value); got != want {
+			t.Fatalf("Bytes(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestBitRate(t *testing.T) {
+	cases := map[float64]string{8e3: "8.0kbit/s", 100e6: "100.0Mbit/s", 2.5e9: "2.5Gbit/s"}
+	for value, want := range cases {
+		if got := BitRate(value); got != want {
+			t.Fatalf("BitRate(%v) = %q, want %q", value, got, want)
+		}
+	}
+}